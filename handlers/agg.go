@@ -2,31 +2,71 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/config"
 	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/metrics"
 	"github.com/mrjacz/gator/internal/rss"
+	"github.com/mrjacz/gator/internal/rules"
 )
 
+// aggOptions holds the flags that tune a single aggregator run
+type aggOptions struct {
+	concurrency int
+	downloadDir string
+	// downloads saves enclosures to downloadDir, nil to download none
+	downloads *enclosureDownloader
+	// maxFailures is how many consecutive failed fetches disable a feed,
+	// 0 to never disable
+	maxFailures int
+	// lease is how long a claimed feed is reserved for this process before
+	// another aggregator may pick it up
+	lease time.Duration
+	// limiter spaces out requests per host, nil to fetch without limits
+	limiter *fetchLimiter
+	// webhooks delivers new posts to webhooks, nil to send none
+	webhooks *webhookDispatcher
+}
+
+const defaultMaxFailures = 10
+
+// defaultLease comfortably covers a fetch; enclosures are downloaded in the
+// background, outside the lease. A worker that dies mid-fetch only delays the
+// feed by this much.
+const defaultLease = 10 * time.Minute
+
+// shutdownGracePeriod is how long a batch in progress may keep running after
+// SIGINT or SIGTERM before its fetches are cancelled
+const shutdownGracePeriod = 30 * time.Second
+
 func Agg(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 {
-		return fmt.Errorf("usage: %v <time_between_reqs> [--concurrency=N]", cmd.Name)
-	}
+	usage := fmt.Errorf("usage: %v <time_between_reqs>|--once [--concurrency=N] [--download-dir=DIR] [--max-failures=N] [--lease=DURATION] [--metrics-addr=ADDR]", cmd.Name)
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
-	if err != nil {
-		return fmt.Errorf("invalid duration: %w", err)
+	var timeBetweenRequests time.Duration
+	once := false
+	metricsAddr := ""
+	opts := aggOptions{
+		concurrency: 1, // default: fetch 1 feed at a time
+		maxFailures: defaultMaxFailures,
+		lease:       defaultLease,
 	}
-
-	concurrency := 1 // default: fetch 1 feed at a time
-	for _, arg := range cmd.Args[1:] {
-		if strings.HasPrefix(arg, "--concurrency=") {
+	for _, arg := range cmd.Args {
+		if arg == "--once" {
+			once = true
+		} else if strings.HasPrefix(arg, "--concurrency=") {
 			concStr := strings.TrimPrefix(arg, "--concurrency=")
 			parsedConc, err := strconv.Atoi(concStr)
 			if err != nil {
@@ -35,44 +75,213 @@ func Agg(s *State, cmd Command) error {
 			if parsedConc < 1 {
 				return fmt.Errorf("concurrency must be >= 1")
 			}
-			concurrency = parsedConc
+			opts.concurrency = parsedConc
+		} else if strings.HasPrefix(arg, "--max-failures=") {
+			parsedMax, err := strconv.Atoi(strings.TrimPrefix(arg, "--max-failures="))
+			if err != nil {
+				return fmt.Errorf("invalid max failures value: %w", err)
+			}
+			if parsedMax < 0 {
+				return fmt.Errorf("max failures must be >= 0")
+			}
+			opts.maxFailures = parsedMax
+		} else if strings.HasPrefix(arg, "--lease=") {
+			parsedLease, err := time.ParseDuration(strings.TrimPrefix(arg, "--lease="))
+			if err != nil {
+				return fmt.Errorf("invalid lease: %w", err)
+			}
+			if parsedLease < time.Second {
+				return fmt.Errorf("lease must be at least 1s")
+			}
+			opts.lease = parsedLease
+		} else if strings.HasPrefix(arg, "--metrics-addr=") {
+			metricsAddr = strings.TrimPrefix(arg, "--metrics-addr=")
+		} else if strings.HasPrefix(arg, "--download-dir=") {
+			opts.downloadDir = strings.TrimPrefix(arg, "--download-dir=")
+			if opts.downloadDir == "" {
+				return fmt.Errorf("download directory must not be empty")
+			}
+			if err := os.MkdirAll(opts.downloadDir, 0o755); err != nil {
+				return fmt.Errorf("couldn't create download directory: %w", err)
+			}
+		} else if timeBetweenRequests == 0 && !strings.HasPrefix(arg, "--") {
+			parsedDuration, err := time.ParseDuration(arg)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if parsedDuration <= 0 {
+				return fmt.Errorf("time between requests must be positive")
+			}
+			timeBetweenRequests = parsedDuration
+		} else {
+			return usage
+		}
+	}
+	if once == (timeBetweenRequests != 0) {
+		return usage
+	}
+
+	if opts.downloadDir != "" {
+		slog.Info("Saving new enclosures", "dir", opts.downloadDir)
+	}
+	opts.limiter = newFetchLimiter(*s.Cfg)
+
+	// ctx is cancelled on SIGINT/SIGTERM and stops the loop. Fetches run on
+	// workCtx instead, so a batch already in progress gets a grace period to
+	// finish before it is cancelled too.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	context.AfterFunc(ctx, func() {
+		slog.Info("Shutting down, waiting for in-flight fetches", "grace_period", shutdownGracePeriod)
+		time.AfterFunc(shutdownGracePeriod, cancelWork)
+	})
+
+	opts.webhooks = newWebhookDispatcher(workCtx, s.DB)
+	// Deliveries and their retries still pending get the same grace period
+	// as fetches
+	defer opts.webhooks.close()
+
+	if opts.downloadDir != "" {
+		opts.downloads = newEnclosureDownloader(workCtx, opts.downloadDir)
+		defer opts.downloads.close()
+	}
+
+	if metricsAddr != "" {
+		metrics.RegisterFeedCollector(s.DB)
+		if err := metrics.Listen(ctx, metricsAddr); err != nil {
+			return fmt.Errorf("couldn't start metrics listener: %w", err)
+		}
+		slog.Info("Serving metrics", "addr", metricsAddr, "path", "/metrics")
+	}
+
+	if once {
+		// A single pass has nothing to reload, and an unhandled SIGHUP would
+		// kill it mid-batch
+		signal.Ignore(syscall.SIGHUP)
+		slog.Info("Collecting all due feeds once", "concurrency", opts.concurrency)
+		deleteUnfollowedFeeds(workCtx, s.DB)
+		scrapeDueFeeds(ctx, workCtx, s, opts)
+		if ctx.Err() != nil {
+			slog.Info("Aggregator stopped")
 		}
+		return nil
 	}
 
-	log.Printf("Collecting feeds every %s with concurrency %d...", timeBetweenRequests, concurrency)
+	slog.Info("Collecting feeds", "interval", timeBetweenRequests, "concurrency", opts.concurrency)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
 	ticker := time.NewTicker(timeBetweenRequests)
+	defer ticker.Stop()
+	// Scheduled digests are checked every minute, between batches
+	digestTicker := time.NewTicker(time.Minute)
+	defer digestTicker.Stop()
 
-	for ; ; <-ticker.C {
-		scrapeFeeds(s, concurrency)
+	for ctx.Err() == nil {
+		deleteUnfollowedFeeds(workCtx, s.DB)
+		scrapeFeeds(workCtx, s, opts)
+		waitForTick(ctx, ticker, digestTicker, hup, s, opts.limiter)
 	}
+
+	slog.Info("Aggregator stopped")
+	return nil
 }
 
-func scrapeFeeds(s *State, concurrency int) {
-	feeds, err := s.DB.GetNextFeedsToFetch(context.Background(), int32(concurrency))
+// waitForTick blocks until the next tick or shutdown, reloading the config
+// whenever SIGHUP arrives and sending digests that fall due in between
+func waitForTick(ctx context.Context, ticker, digestTicker *time.Ticker, hup <-chan os.Signal, s *State, limiter *fetchLimiter) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reloadConfig(s, limiter)
+		case <-digestTicker.C:
+			sendDueDigests(ctx, s)
+		case <-ticker.C:
+			return
+		}
+	}
+}
+
+// reloadConfig re-reads the config file on SIGHUP and applies the new rate
+// limits. The database connection is kept open, so a changed db_url only
+// takes effect after a restart.
+func reloadConfig(s *State, limiter *fetchLimiter) {
+	cfg, err := config.Read()
 	if err != nil {
-		log.Println("Couldn't get next feeds to fetch", err)
+		slog.Error("Couldn't reload config", "error", err)
 		return
 	}
+	if cfg.DBURL != s.Cfg.DBURL {
+		slog.Warn("db_url changed, restart the aggregator to use the new database")
+	}
+	*s.Cfg = cfg
+	limiter.configure(cfg)
+	slog.Info("Config reloaded")
+}
+
+// scrapeFeeds claims the next batch of due feeds, fetches them and returns
+// their IDs. Claimed feeds are leased to this process, so aggregators sharing
+// a database never fetch the same feed at the same time.
+func scrapeFeeds(ctx context.Context, s *State, opts aggOptions) []uuid.UUID {
+	feeds, err := s.DB.ClaimFeedsToFetch(ctx, database.ClaimFeedsToFetchParams{
+		LeaseSeconds: int32(opts.lease / time.Second),
+		MaxFeeds:     int32(opts.concurrency),
+	})
+	if err != nil {
+		slog.Error("Couldn't claim next feeds to fetch", "error", err)
+		return nil
+	}
 
 	if len(feeds) == 0 {
-		log.Println("No feeds to fetch")
-		return
+		slog.Debug("No feeds due for fetching")
+		return nil
 	}
 
-	log.Printf("Found %d feed(s) to fetch!", len(feeds))
+	slog.Info("Claimed feeds to fetch", "count", len(feeds))
 
 	var wg sync.WaitGroup
-	for _, feed := range feeds {
+	feedIDs := make([]uuid.UUID, len(feeds))
+	for i, feed := range feeds {
+		feedIDs[i] = feed.ID
 		wg.Add(1)
 		go func(f database.Feed) {
 			defer wg.Done()
-			scrapeFeed(s.DB, f)
+			scrapeFeed(ctx, s.DB, f, opts)
+			releaseFeedLease(context.WithoutCancel(ctx), s.DB, f)
 		}(feed)
 	}
 
 	wg.Wait()
-	log.Println("Finished fetching all feeds in this batch")
+	slog.Info("Finished fetching batch", "count", len(feeds))
+	return feedIDs
+}
+
+// scrapeDueFeeds fetches batches on workCtx until no feed is due any more or
+// ctx is cancelled. A batch made up only of feeds already fetched in this run
+// (because rescheduling them failed) also ends it, so a broken feed can't
+// keep it going forever.
+func scrapeDueFeeds(ctx, workCtx context.Context, s *State, opts aggOptions) {
+	fetched := make(map[uuid.UUID]bool)
+	for ctx.Err() == nil {
+		feedIDs := scrapeFeeds(workCtx, s, opts)
+
+		progressed := false
+		for _, id := range feedIDs {
+			if !fetched[id] {
+				fetched[id] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			return
+		}
+	}
 }
 
 func parsePublishedAt(pubDate string) (time.Time, error) {
@@ -83,7 +292,9 @@ func parsePublishedAt(pubDate string) (time.Time, error) {
 		time.RFC822Z,
 		time.RFC822,
 		"2006-01-02T15:04:05Z07:00", // ISO 8601
+		"2006-01-02T15:04Z07:00",    // W3CDTF without seconds (dc:date)
 		"2006-01-02 15:04:05",
+		"2006-01-02", // W3CDTF date only (dc:date)
 	}
 
 	for _, format := range formats {
@@ -96,49 +307,304 @@ func parsePublishedAt(pubDate string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date: %s", pubDate)
 }
 
-func scrapeFeed(db *database.Queries, feed database.Feed) {
-	_, err := db.MarkFeedFetched(context.Background(), feed.ID)
+// feedFetch is the outcome of a single scrapeFeed call
+type feedFetch struct {
+	database.CreateFeedFetchParams
+	newItems []rss.Item
+}
+
+func scrapeFeed(ctx context.Context, db *database.Queries, feed database.Feed, opts aggOptions) (fetch feedFetch) {
+	logger := feedLogger(feed)
+	fetch.CreateFeedFetchParams = database.CreateFeedFetchParams{
+		ID:        uuid.New(),
+		FeedID:    feed.ID,
+		StartedAt: time.Now(),
+	}
+
+	if opts.limiter != nil && !waitForHost(ctx, db, feed, opts.limiter) {
+		return
+	}
+
+	_, err := db.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
-		log.Printf("Couldn't mark feed %s fetched: %v", feed.Name, err)
+		logger.Error("Couldn't mark feed fetched", "error", err)
+		fetch.Error = sql.NullString{String: err.Error(), Valid: true}
 		return
 	}
 
-	feedData, err := rss.FetchFeed(context.Background(), feed.Url)
+	var fetchDuration time.Duration
+	defer func() {
+		metrics.ObserveFetch(int(fetch.HttpStatus.Int32), fetchDuration)
+		// Log the attempt even when it was cut short by a shutdown
+		recordFeedFetch(context.WithoutCancel(ctx), db, feed, fetch.CreateFeedFetchParams)
+	}()
+
+	fetchStarted := time.Now()
+	feedData, err := rss.FetchFeed(ctx, feed.Url, rss.Validators{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+	})
+	fetchDuration = time.Since(fetchStarted)
+	var notModified *rss.NotModifiedError
+	if errors.As(err, &notModified) {
+		logger.Info("Feed not modified since last fetch")
+		fetch.HttpStatus = sql.NullInt32{Int32: http.StatusNotModified, Valid: true}
+		recordFeedSuccess(ctx, db, feed)
+		scheduleNextFetch(ctx, db, feed, notModified.Schedule)
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		// Cancelled by a shutdown, not the feed's fault; it stays due
+		logger.Warn("Fetch interrupted", "error", err)
+		fetch.Error = sql.NullString{String: err.Error(), Valid: true}
+		return
+	}
 	if err != nil {
-		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
+		logger.Warn("Couldn't collect feed", "error", err)
+		fetch.Error = sql.NullString{String: err.Error(), Valid: true}
+		var statusErr *rss.StatusError
+		if errors.As(err, &statusErr) {
+			fetch.HttpStatus = sql.NullInt32{Int32: int32(statusErr.StatusCode), Valid: true}
+			deferHost(feed, statusErr, opts)
+		}
+		var parseErr *rss.ParseError
+		if errors.As(err, &parseErr) {
+			fetch.HttpStatus = sql.NullInt32{Int32: int32(parseErr.StatusCode), Valid: true}
+			metrics.ParseFailuresTotal.WithLabelValues("feed").Inc()
+		}
+		recordFeedFailure(ctx, db, feed, err, opts)
 		return
 	}
+	recordFeedSuccess(ctx, db, feed)
+
+	fetch.HttpStatus = sql.NullInt32{Int32: int32(feedData.StatusCode), Valid: true}
+	fetch.Bytes = feedData.Size
+	fetch.ItemCount = int32(len(feedData.Items))
+
+	// The followers' filter rules and webhooks, loaded with the first new post
+	var matchers []*rules.Matcher
+	var hooks []webhookTarget
+	for _, item := range feedData.Items {
+		if ctx.Err() != nil {
+			logger.Warn("Fetch interrupted", "error", ctx.Err())
+			fetch.Error = sql.NullString{String: ctx.Err().Error(), Valid: true}
+			return
+		}
 
-	for _, item := range feedData.Channel.Item {
 		publishedAt, err := parsePublishedAt(item.PubDate)
 		if err != nil {
-			log.Printf("Couldn't parse published date for post '%s': %v", item.Title, err)
+			logger.Warn("Couldn't parse published date", "post_guid", item.GUID, "post_title", item.Title, "error", err)
+			metrics.ParseFailuresTotal.WithLabelValues("date").Inc()
 			continue
 		}
 
-		_, err = db.CreatePost(context.Background(), database.CreatePostParams{
+		if item.Link != "" && item.GUID != item.Link {
+			adoptPostGUID(ctx, db, feed, item)
+		}
+
+		now := time.Now()
+		post, err := db.UpsertPost(ctx, database.UpsertPostParams{
 			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
 			Title:       item.Title,
 			Url:         item.Link,
 			Description: item.Description,
 			PublishedAt: publishedAt,
 			FeedID:      feed.ID,
+			Content:     item.Content,
+			Guid:        item.GUID,
+			RevisionID:  uuid.New(),
 		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Already stored and unchanged since
+			metrics.DuplicatesSkippedTotal.Inc()
+			continue
+		}
 		if err != nil {
-			// Check if it's a duplicate URL error
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				// Ignore duplicate posts
-				continue
-			}
-			// Log other errors
-			log.Printf("Couldn't create post '%s': %v", item.Title, err)
+			logger.Error("Couldn't save post", "post_guid", item.GUID, "post_title", item.Title, "error", err)
 			continue
 		}
 
-		log.Printf("Post created: %s", item.Title)
+		if !post.Inserted {
+			logger.Info("Post updated", "post_id", post.ID, "post_title", item.Title)
+			fetch.UpdatedPostCount++
+			metrics.PostsUpdatedTotal.Inc()
+			saveEnclosures(ctx, db, feed, post.ID, item, opts)
+			continue
+		}
+
+		logger.Info("Post created", "post_id", post.ID, "post_title", item.Title)
+		fetch.NewPostCount++
+		metrics.PostsCreatedTotal.Inc()
+		fetch.newItems = append(fetch.newItems, item)
+		saveEnclosures(ctx, db, feed, post.ID, item, opts)
+
+		if matchers == nil {
+			matchers = loadFilterRules(ctx, db, feed)
+		}
+		hiddenFor := applyFilterRules(ctx, db, feed, post.ID, item, matchers)
+
+		if opts.webhooks != nil {
+			if hooks == nil {
+				hooks = loadWebhooks(ctx, db, feed)
+			}
+			queueWebhooks(opts.webhooks, feed, post, hooks, hiddenFor)
+		}
+	}
+
+	// Only remember the validators after the items have been processed, so
+	// a run interrupted part-way is retried with a full download
+	if feedData.Validators.ETag != feed.Etag.String || feedData.Validators.LastModified != feed.LastModified.String {
+		err = db.UpdateFeedValidators(ctx, database.UpdateFeedValidatorsParams{
+			ID:           feed.ID,
+			Etag:         sql.NullString{String: feedData.Validators.ETag, Valid: feedData.Validators.ETag != ""},
+			LastModified: sql.NullString{String: feedData.Validators.LastModified, Valid: feedData.Validators.LastModified != ""},
+		})
+		if err != nil {
+			logger.Error("Couldn't save cache validators", "error", err)
+		}
+	}
+
+	scheduleNextFetch(ctx, db, feed, feedData.Schedule)
+
+	logger.Info("Feed collected", "items", len(feedData.Items), "new_posts", fetch.NewPostCount, "updated_posts", fetch.UpdatedPostCount)
+	return
+}
+
+// adoptPostGUID matches an item to a post stored before 009_posts_guid, which
+// has its URL as GUID, so the item updates that post instead of duplicating it
+func adoptPostGUID(ctx context.Context, db *database.Queries, feed database.Feed, item rss.Item) {
+	adopted, err := db.AdoptPostGUID(ctx, database.AdoptPostGUIDParams{
+		Guid:   item.GUID,
+		FeedID: feed.ID,
+		Url:    item.Link,
+	})
+	if err != nil {
+		feedLogger(feed).Error("Couldn't match post to its GUID", "post_guid", item.GUID, "post_url", item.Link, "error", err)
+		return
+	}
+	if adopted > 0 {
+		feedLogger(feed).Info("Post matched to its GUID", "post_guid", item.GUID, "post_url", item.Link)
+	}
+}
+
+// recordFeedFetch appends a finished fetch attempt to the feed's fetch log
+func recordFeedFetch(ctx context.Context, db *database.Queries, feed database.Feed, fetch database.CreateFeedFetchParams) {
+	fetch.FinishedAt = time.Now()
+	_, err := db.CreateFeedFetch(ctx, fetch)
+	if err != nil {
+		feedLogger(feed).Error("Couldn't log fetch", "error", err)
+	}
+}
+
+// deferHost backs off the whole host of a feed that answered with
+// Retry-After or 429 Too Many Requests, since its other feeds on the same
+// host are likely to be refused too
+func deferHost(feed database.Feed, statusErr *rss.StatusError, opts aggOptions) {
+	if opts.limiter == nil {
+		return
+	}
+	delay := statusErr.RetryAfter
+	if delay == 0 && statusErr.StatusCode == http.StatusTooManyRequests {
+		delay = minRetryInterval
+	}
+	if delay > 0 {
+		feedLogger(feed).Info("Deferring host", "host", feedHost(feed.Url), "delay", delay)
+		opts.limiter.deferHost(feedHost(feed.Url), delay)
+	}
+}
+
+// unfollowedFeedGracePeriod keeps a new feed that nobody follows yet, as
+// addfeed creates the feed just before following it
+const unfollowedFeedGracePeriod = 24 * time.Hour
+
+// deleteUnfollowedFeeds garbage-collects feeds nobody follows, so they are
+// no longer fetched
+func deleteUnfollowedFeeds(ctx context.Context, db *database.Queries) {
+	feeds, err := db.DeleteUnfollowedFeeds(ctx, time.Now().Add(-unfollowedFeedGracePeriod))
+	if err != nil {
+		slog.Error("Couldn't delete unfollowed feeds", "error", err)
+		return
+	}
+	for _, feed := range feeds {
+		feedLogger(feed).Info("Deleted feed nobody follows")
+	}
+}
+
+// feedLogger returns a logger carrying the attributes that identify feed
+func feedLogger(feed database.Feed) *slog.Logger {
+	return slog.With("feed_id", feed.ID, "feed_url", feed.Url, "feed_name", feed.Name)
+}
+
+// releaseFeedLease lets other aggregators claim the feed again as soon as it
+// is next due, instead of waiting for the lease to expire. feed must be the
+// row returned by the claim, so that a lease that expired and was taken by
+// another aggregator is left alone.
+func releaseFeedLease(ctx context.Context, db *database.Queries, feed database.Feed) {
+	err := db.ReleaseFeedLease(ctx, database.ReleaseFeedLeaseParams{
+		ID:             feed.ID,
+		LeaseExpiresAt: feed.LeaseExpiresAt,
+	})
+	if err != nil {
+		feedLogger(feed).Error("Couldn't release lease", "error", err)
 	}
+}
+
+func recordFeedSuccess(ctx context.Context, db *database.Queries, feed database.Feed) {
+	err := db.RecordFeedSuccess(ctx, feed.ID)
+	if err != nil {
+		feedLogger(feed).Error("Couldn't record successful fetch", "error", err)
+	}
+}
 
-	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
+// recordFeedFailure stores the error, then either disables the feed or backs
+// off exponentially before the next attempt
+func recordFeedFailure(ctx context.Context, db *database.Queries, feed database.Feed, fetchErr error, opts aggOptions) {
+	updated, err := db.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		LastError:   sql.NullString{String: fetchErr.Error(), Valid: true},
+		MaxFailures: int32(opts.maxFailures),
+		ID:          feed.ID,
+	})
+	if err != nil {
+		feedLogger(feed).Error("Couldn't record failed fetch", "error", err)
+		return
+	}
+
+	if updated.Disabled {
+		feedLogger(feed).Warn("Feed disabled", "consecutive_failures", updated.ConsecutiveFailures)
+		return
+	}
+
+	var retryAfter time.Duration
+	var statusErr *rss.StatusError
+	if errors.As(fetchErr, &statusErr) {
+		retryAfter = statusErr.RetryAfter
+	}
+	scheduleRetry(ctx, db, updated, retryAfter)
+}
+
+func saveEnclosures(ctx context.Context, db *database.Queries, feed database.Feed, postID uuid.UUID, item rss.Item, opts aggOptions) {
+	for _, enclosure := range item.Enclosures {
+		_, err := db.UpsertPostEnclosure(ctx, database.UpsertPostEnclosureParams{
+			ID:              uuid.New(),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+			PostID:          postID,
+			Url:             enclosure.URL,
+			MimeType:        enclosure.Type,
+			Length:          enclosure.Length,
+			DurationSeconds: sql.NullInt32{Int32: int32(enclosure.Duration), Valid: enclosure.Duration > 0},
+			Episode:         sql.NullInt32{Int32: int32(item.Episode), Valid: item.Episode > 0},
+			ImageUrl:        sql.NullString{String: item.Image, Valid: item.Image != ""},
+		})
+		if err != nil {
+			feedLogger(feed).Error("Couldn't save enclosure", "post_id", postID, "enclosure_url", enclosure.URL, "error", err)
+			continue
+		}
+
+		if opts.downloads != nil {
+			opts.downloads.send(enclosureDownload{feed: feed, postID: postID, url: enclosure.URL})
+		}
+	}
 }
@@ -12,18 +12,12 @@ import (
 
 func Bookmark(s *State, cmd Command, user database.User) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %s <post_url>", cmd.Name)
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
 	}
 
-	postURL := cmd.Args[0]
-
-	// Find the post by URL
-	post, err := s.DB.GetPostByURL(context.Background(), database.GetPostByURLParams{
-		UserID: user.ID,
-		Url:    postURL,
-	})
+	post, err := findPost(s, user, cmd.Args[0])
 	if err != nil {
-		return fmt.Errorf("post not found with URL: %s", postURL)
+		return err
 	}
 
 	// Check if already bookmarked
@@ -57,18 +51,12 @@ func Bookmark(s *State, cmd Command, user database.User) error {
 
 func Unbookmark(s *State, cmd Command, user database.User) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %s <post_url>", cmd.Name)
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
 	}
 
-	postURL := cmd.Args[0]
-
-	// Find the post by URL
-	post, err := s.DB.GetPostByURL(context.Background(), database.GetPostByURLParams{
-		UserID: user.ID,
-		Url:    postURL,
-	})
+	post, err := findPost(s, user, cmd.Args[0])
 	if err != nil {
-		return fmt.Errorf("post not found with URL: %s", postURL)
+		return err
 	}
 
 	// Delete bookmark
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/mrjacz/gator/internal/database"
 )
 
@@ -14,8 +15,12 @@ func Browse(s *State, cmd Command, user database.User) error {
 	page := 1 // default page
 	sortBy := "date" // default sort by date
 	var feedURL string
+	var tag string
+	var folder string
+	full := false
+	unreadOnly := false
 
-	// Parse arguments: browse [limit] [--sort=title|date] [--feed=url] [--page=N]
+	// Parse arguments: browse [limit] [--sort=title|date] [--feed=url] [--folder=name] [--tag=name] [--unread] [--page=N] [--full]
 	for _, arg := range cmd.Args {
 		if strings.HasPrefix(arg, "--sort=") {
 			sortBy = strings.TrimPrefix(arg, "--sort=")
@@ -24,6 +29,14 @@ func Browse(s *State, cmd Command, user database.User) error {
 			}
 		} else if strings.HasPrefix(arg, "--feed=") {
 			feedURL = strings.TrimPrefix(arg, "--feed=")
+		} else if strings.HasPrefix(arg, "--folder=") {
+			folder = strings.TrimPrefix(arg, "--folder=")
+		} else if strings.HasPrefix(arg, "--tag=") {
+			tag = strings.TrimPrefix(arg, "--tag=")
+		} else if arg == "--full" {
+			full = true
+		} else if arg == "--unread" {
+			unreadOnly = true
 		} else if strings.HasPrefix(arg, "--page=") {
 			pageStr := strings.TrimPrefix(arg, "--page=")
 			parsedPage, err := strconv.Atoi(pageStr)
@@ -53,22 +66,41 @@ func Browse(s *State, cmd Command, user database.User) error {
 	// Fetch posts based on filters
 	if feedURL != "" {
 		posts, err = s.DB.GetPostsForUserByFeed(context.Background(), database.GetPostsForUserByFeedParams{
-			UserID: user.ID,
-			Url:    feedURL,
-			Limit:  int32(limit),
-			Offset: int32(offset),
+			UserID:     user.ID,
+			Url:        feedURL,
+			UnreadOnly: unreadOnly,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+		})
+	} else if folder != "" {
+		posts, err = s.DB.GetPostsForUserByFolder(context.Background(), database.GetPostsForUserByFolderParams{
+			UserID:     user.ID,
+			FolderName: folder,
+			UnreadOnly: unreadOnly,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
+		})
+	} else if tag != "" {
+		posts, err = s.DB.GetPostsForUserByTag(context.Background(), database.GetPostsForUserByTagParams{
+			UserID:     user.ID,
+			Tag:        tag,
+			UnreadOnly: unreadOnly,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
 		})
 	} else if sortBy == "title" {
 		posts, err = s.DB.GetPostsForUserSortedByTitle(context.Background(), database.GetPostsForUserSortedByTitleParams{
-			UserID: user.ID,
-			Limit:  int32(limit),
-			Offset: int32(offset),
+			UserID:     user.ID,
+			UnreadOnly: unreadOnly,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
 		})
 	} else {
 		posts, err = s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-			UserID: user.ID,
-			Limit:  int32(limit),
-			Offset: int32(offset),
+			UserID:     user.ID,
+			UnreadOnly: unreadOnly,
+			Limit:      int32(limit),
+			Offset:     int32(offset),
 		})
 	}
 
@@ -81,9 +113,26 @@ func Browse(s *State, cmd Command, user database.User) error {
 		return nil
 	}
 
+	// Show each post's feed under the name the user gave it
+	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	feedNames := make(map[uuid.UUID]string, len(feedFollows))
+	for _, ff := range feedFollows {
+		feedNames[ff.FeedID] = ff.FeedName
+	}
+
 	fmt.Printf("Found %d posts for user %s", len(posts), user.Name)
 	if feedURL != "" {
 		fmt.Printf(" (filtered by feed: %s)", feedURL)
+	} else if folder != "" {
+		fmt.Printf(" (in folder: %s)", folder)
+	} else if tag != "" {
+		fmt.Printf(" (tagged: %s)", tag)
+	}
+	if unreadOnly {
+		fmt.Printf(" (unread only)")
 	}
 	if sortBy == "title" {
 		fmt.Printf(" (sorted by title)")
@@ -98,9 +147,15 @@ func Browse(s *State, cmd Command, user database.User) error {
 	for _, post := range posts {
 		fmt.Printf("\n===================\n")
 		fmt.Printf("Title: %s\n", post.Title)
+		fmt.Printf("ID: %s\n", post.ID)
+		fmt.Printf("Feed: %s\n", feedNames[post.FeedID])
 		fmt.Printf("URL: %s\n", post.Url)
 		fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05"))
-		fmt.Printf("Description: %s\n", post.Description)
+		if full {
+			fmt.Printf("\n%s\n", postBody(post.Content, post.Description))
+		} else {
+			fmt.Printf("Description: %s\n", post.Description)
+		}
 	}
 
 	return nil
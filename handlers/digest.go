@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mrjacz/gator/internal/config"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/digest"
+)
+
+const (
+	defaultDigestSince = 24 * time.Hour
+	// maxDigestPosts keeps a digest after a long absence readable
+	maxDigestPosts      = 200
+	digestSummaryLength = 280
+	// digestFrom is the sender of .eml files when no SMTP server is configured
+	digestFrom = "gator <gator@localhost>"
+)
+
+// Digest prints a summary of the user's new posts, grouped by feed, or
+// emails it with --to or saves it as a .eml file with --out
+func Digest(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [--since DURATION] [--to ADDRESS] [--format html|text] [--out FILE.eml]", cmd.Name)
+
+	since := defaultDigestSince
+	var to, format, out string
+	for i := 0; i < len(cmd.Args); i++ {
+		name, value, ok := strings.Cut(cmd.Args[i], "=")
+		if !ok && i+1 < len(cmd.Args) {
+			i++
+			value = cmd.Args[i]
+		} else if !ok {
+			return usage
+		}
+
+		switch name {
+		case "--since":
+			parsedSince, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if parsedSince <= 0 {
+				return fmt.Errorf("since must be positive")
+			}
+			since = parsedSince
+		case "--to":
+			to = value
+		case "--format":
+			if err := digest.ValidateFormat(value); err != nil {
+				return err
+			}
+			format = value
+		case "--out":
+			out = value
+		default:
+			return usage
+		}
+	}
+	if format == "" {
+		// HTML is meant for mail clients, the terminal gets plain text
+		format = digest.FormatHTML
+		if to == "" && out == "" {
+			format = digest.FormatText
+		}
+	}
+
+	now := time.Now()
+	d, err := buildDigest(context.Background(), s.DB, user, now.Add(-since), now)
+	if err != nil {
+		return err
+	}
+	if d.PostCount() == 0 {
+		fmt.Printf("No new posts since %s.\n", d.Since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	if to == "" && out == "" {
+		rendered, err := digest.Render(d, format)
+		if err != nil {
+			return fmt.Errorf("couldn't render digest: %w", err)
+		}
+		fmt.Print(rendered)
+		return nil
+	}
+
+	from := digestFrom
+	if s.Cfg.SMTP != nil && s.Cfg.SMTP.From != "" {
+		from = s.Cfg.SMTP.From
+	}
+	recipient := to
+	if recipient == "" {
+		recipient = "undisclosed-recipients:;"
+	}
+	msg, err := digest.Message(d, format, from, recipient)
+	if err != nil {
+		return fmt.Errorf("couldn't build digest email: %w", err)
+	}
+
+	if out != "" {
+		if err := os.WriteFile(out, msg, 0o644); err != nil {
+			return fmt.Errorf("couldn't write digest: %w", err)
+		}
+		fmt.Printf("Digest with %d posts written to %s\n", d.PostCount(), out)
+	}
+	if to != "" {
+		if err := digest.Send(s.Cfg.SMTP, to, msg); err != nil {
+			return fmt.Errorf("couldn't send digest: %w", err)
+		}
+		fmt.Printf("Digest with %d posts sent to %s\n", d.PostCount(), to)
+	}
+	return nil
+}
+
+// buildDigest collects the posts stored for user between since and until
+func buildDigest(ctx context.Context, db *database.Queries, user database.User, since, until time.Time) (digest.Digest, error) {
+	posts, err := db.GetDigestPostsForUser(ctx, database.GetDigestPostsForUserParams{
+		UserID:    user.ID,
+		CreatedAt: since,
+		Limit:     maxDigestPosts,
+	})
+	if err != nil {
+		return digest.Digest{}, fmt.Errorf("couldn't get posts: %w", err)
+	}
+
+	d := digest.Digest{User: user.Name, Since: since, Until: until}
+	for _, post := range posts {
+		// Posts arrive ordered by feed
+		if len(d.Feeds) == 0 || d.Feeds[len(d.Feeds)-1].URL != post.FeedUrl {
+			d.Feeds = append(d.Feeds, digest.Feed{Name: post.FeedName, URL: post.FeedUrl})
+		}
+		feed := &d.Feeds[len(d.Feeds)-1]
+		feed.Posts = append(feed.Posts, digest.Post{
+			Title:       post.Title,
+			URL:         post.Url,
+			Summary:     digest.Summarize(htmlToText(post.Description), digestSummaryLength),
+			PublishedAt: post.PublishedAt,
+		})
+	}
+	return d, nil
+}
+
+// digestSendTime returns today's time of day to send digests at, parsed
+// from the config's HH:MM
+func digestSendTime(cfg *config.DigestConfig, now time.Time) (time.Time, error) {
+	t, err := time.Parse("15:04", cfg.SendAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid digest send_at %q (must be HH:MM)", cfg.SendAt)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+// sendDueDigests emails the daily digest to every configured recipient once
+// today's send time has passed. Each send is claimed in the database first,
+// so aggregators sharing it send every digest only once; a failed send is
+// not retried until the next day.
+func sendDueDigests(ctx context.Context, s *State) {
+	cfg := s.Cfg.Digest
+	if cfg == nil || cfg.SendAt == "" {
+		return
+	}
+
+	now := time.Now()
+	sendAt, err := digestSendTime(cfg, now)
+	if err != nil {
+		slog.Error("Couldn't schedule digests", "error", err)
+		return
+	}
+	if now.Before(sendAt) {
+		return
+	}
+
+	since := defaultDigestSince
+	if cfg.Since != "" {
+		since, err = time.ParseDuration(cfg.Since)
+		if err != nil {
+			slog.Error("Couldn't schedule digests", "error", fmt.Errorf("invalid digest since: %w", err))
+			return
+		}
+	}
+	format := digest.FormatHTML
+	if cfg.Format != "" {
+		format = cfg.Format
+	}
+	if err := digest.ValidateFormat(format); err != nil {
+		slog.Error("Couldn't schedule digests", "error", err)
+		return
+	}
+	if s.Cfg.SMTP == nil {
+		slog.Error("Couldn't schedule digests", "error", "smtp is not configured")
+		return
+	}
+
+	for userName, to := range cfg.Recipients {
+		logger := slog.With("user_name", userName, "to", to)
+
+		user, err := s.DB.GetUser(ctx, userName)
+		if err != nil {
+			logger.Error("Couldn't get digest recipient", "error", err)
+			continue
+		}
+
+		claimed, err := s.DB.ClaimDigestSend(ctx, database.ClaimDigestSendParams{
+			UserID:    user.ID,
+			SentAt:    now,
+			NotBefore: sendAt,
+		})
+		if err != nil {
+			logger.Error("Couldn't claim digest", "error", err)
+			continue
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		d, err := buildDigest(ctx, s.DB, user, now.Add(-since), now)
+		if err != nil {
+			logger.Error("Couldn't build digest", "error", err)
+			continue
+		}
+		if d.PostCount() == 0 {
+			logger.Info("No new posts, skipping digest")
+			continue
+		}
+
+		msg, err := digest.Message(d, format, s.Cfg.SMTP.From, to)
+		if err == nil {
+			err = digest.Send(s.Cfg.SMTP, to, msg)
+		}
+		if err != nil {
+			logger.Error("Couldn't send digest", "error", err)
+			continue
+		}
+		logger.Info("Digest sent", "posts", d.PostCount(), "feeds", len(d.Feeds))
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+func Enclosures(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
+	}
+
+	post, err := findPost(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	enclosures, err := s.DB.GetEnclosuresForPost(context.Background(), post.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get enclosures: %w", err)
+	}
+
+	if len(enclosures) == 0 {
+		fmt.Printf("No enclosures found for: %s\n", post.Title)
+		return nil
+	}
+
+	fmt.Printf("Found %d enclosure(s) for: %s\n", len(enclosures), post.Title)
+	for _, enclosure := range enclosures {
+		fmt.Printf("\n===================\n")
+		fmt.Printf("URL: %s\n", enclosure.Url)
+		if enclosure.MimeType != "" {
+			fmt.Printf("Type: %s\n", enclosure.MimeType)
+		}
+		if enclosure.Length > 0 {
+			fmt.Printf("Size: %d bytes\n", enclosure.Length)
+		}
+		if enclosure.DurationSeconds.Valid {
+			fmt.Printf("Duration: %s\n", time.Duration(enclosure.DurationSeconds.Int32)*time.Second)
+		}
+		if enclosure.Episode.Valid {
+			fmt.Printf("Episode: %d\n", enclosure.Episode.Int32)
+		}
+		if enclosure.ImageUrl.Valid {
+			fmt.Printf("Image: %s\n", enclosure.ImageUrl.String)
+		}
+	}
+
+	return nil
+}
+
+const (
+	enclosureWorkers   = 2
+	enclosureQueueSize = 1000
+)
+
+// enclosureClient downloads enclosures; the timeout leaves room for large
+// podcast episodes
+var enclosureClient = &http.Client{Timeout: 5 * time.Minute}
+
+// enclosureDownload is one enclosure waiting to be saved
+type enclosureDownload struct {
+	feed   database.Feed
+	postID uuid.UUID
+	url    string
+}
+
+// enclosureDownloader saves enclosures in the background, so that slow
+// downloads never hold a feed's lease or the fetch that found them
+type enclosureDownloader struct {
+	ctx     context.Context
+	dir     string
+	queue   chan enclosureDownload
+	workers sync.WaitGroup
+}
+
+// newEnclosureDownloader starts the download workers. Downloads still queued
+// when ctx is cancelled are dropped.
+func newEnclosureDownloader(ctx context.Context, dir string) *enclosureDownloader {
+	d := &enclosureDownloader{
+		ctx:   ctx,
+		dir:   dir,
+		queue: make(chan enclosureDownload, enclosureQueueSize),
+	}
+	for range enclosureWorkers {
+		d.workers.Add(1)
+		go d.run()
+	}
+	return d
+}
+
+// send queues a download, dropping it when the queue is full rather than
+// holding up the fetch
+func (d *enclosureDownloader) send(download enclosureDownload) {
+	select {
+	case d.queue <- download:
+	default:
+		feedLogger(download.feed).Warn("Download queue full, enclosure skipped", "post_id", download.postID, "enclosure_url", download.url)
+	}
+}
+
+// close waits for the queued downloads to finish, then stops the workers
+func (d *enclosureDownloader) close() {
+	close(d.queue)
+	d.workers.Wait()
+}
+
+func (d *enclosureDownloader) run() {
+	defer d.workers.Done()
+	for download := range d.queue {
+		logger := feedLogger(download.feed).With("post_id", download.postID, "enclosure_url", download.url)
+		if d.ctx.Err() != nil {
+			logger.Warn("Enclosure download dropped on shutdown")
+			continue
+		}
+		path, err := downloadEnclosure(d.ctx, d.dir, download.feed.Name, download.url)
+		if err != nil {
+			logger.Error("Couldn't download enclosure", "error", err)
+			continue
+		}
+		logger.Info("Enclosure saved", "path", path)
+	}
+}
+
+// downloadEnclosure saves an enclosure under dir/<feed name>/ and returns the
+// path it was written to. Files that already exist are left untouched.
+func downloadEnclosure(ctx context.Context, dir, feedName, enclosureURL string) (string, error) {
+	u, err := url.Parse(enclosureURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid enclosure URL: %w", err)
+	}
+
+	fileName := sanitizeFileName(path.Base(u.Path))
+	if fileName == "" {
+		return "", fmt.Errorf("couldn't derive a file name from %s", enclosureURL)
+	}
+
+	feedDir := filepath.Join(dir, sanitizeFileName(feedName))
+	if err := os.MkdirAll(feedDir, 0o755); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(feedDir, fileName)
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", enclosureURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	resp, err := enclosureClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	// Write to a temporary file first so an interrupted download never
+	// leaves a truncated file that would be skipped next time
+	tmp, err := os.CreateTemp(feedDir, ".gator-*.part")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", err
+	}
+
+	return dest, nil
+}
+
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -50,29 +52,267 @@ func AddFeed(s *State, cmd Command, user database.User) error {
 }
 
 func ListFeeds(s *State, cmd Command) error {
-	feeds, err := s.DB.GetFeeds(context.Background())
+	broken := false
+	for _, arg := range cmd.Args {
+		if arg != "--broken" {
+			return fmt.Errorf("usage: %s [--broken]", cmd.Name)
+		}
+		broken = true
+	}
+
+	var feeds []database.Feed
+	var err error
+	if broken {
+		feeds, err = s.DB.GetBrokenFeeds(context.Background())
+	} else {
+		feeds, err = s.DB.GetFeeds(context.Background())
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't get feeds: %w", err)
 	}
 
 	if len(feeds) == 0 {
-		fmt.Println("No feeds found.")
+		if broken {
+			fmt.Println("No broken feeds found.")
+		} else {
+			fmt.Println("No feeds found.")
+		}
 		return nil
 	}
 
-	fmt.Printf("Found %d feeds:\n", len(feeds))
+	if broken {
+		fmt.Printf("Found %d broken feeds:\n", len(feeds))
+	} else {
+		fmt.Printf("Found %d feeds:\n", len(feeds))
+	}
 	for _, feed := range feeds {
 		user, err := s.DB.GetUserById(context.Background(), feed.UserID)
 		if err != nil {
 			return fmt.Errorf("couldn't get user: %w", err)
 		}
 		printFeed(feed, user)
+		if broken {
+			printFeedHealth(feed)
+		}
 		fmt.Println("=====================================")
 	}
 
 	return nil
 }
 
+func feedEnable(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <feed_url>", cmd.Name)
+	}
+
+	feed, err := getOwnedFeed(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	feed, err = s.DB.EnableFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't enable feed: %w", err)
+	}
+
+	fmt.Printf("%s enabled, it will be fetched on the next aggregator run.\n", feed.Name)
+	return nil
+}
+
+const defaultFeedLogLimit = 10
+
+func feedLog(s *State, cmd Command, user database.User) error {
+	var feedURL string
+	limit := defaultFeedLogLimit
+	for i := 0; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		var limitStr string
+		if strings.HasPrefix(arg, "--limit=") {
+			limitStr = strings.TrimPrefix(arg, "--limit=")
+		} else if arg == "--limit" && i+1 < len(cmd.Args) {
+			i++
+			limitStr = cmd.Args[i]
+		} else if feedURL == "" && !strings.HasPrefix(arg, "--") {
+			feedURL = arg
+			continue
+		} else {
+			return fmt.Errorf("usage: %s <feed_url> [--limit N]", cmd.Name)
+		}
+
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return fmt.Errorf("invalid limit: %w", err)
+		}
+		if parsedLimit < 1 {
+			return fmt.Errorf("limit must be >= 1")
+		}
+		limit = parsedLimit
+	}
+	if feedURL == "" {
+		return fmt.Errorf("usage: %s <feed_url> [--limit N]", cmd.Name)
+	}
+
+	feed, err := s.DB.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	fetches, err := s.DB.GetFeedFetches(context.Background(), database.GetFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get fetch log: %w", err)
+	}
+
+	if len(fetches) == 0 {
+		fmt.Printf("%s has not been fetched yet.\n", feed.Name)
+		return nil
+	}
+
+	fmt.Printf("Last %d fetches of %s:\n", len(fetches), feed.Name)
+	for _, fetch := range fetches {
+		printFeedFetch(fetch)
+		fmt.Println("=====================================")
+	}
+	return nil
+}
+
+func feedRename(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <feed_url> <new_name>", cmd.Name)
+	}
+
+	feed, err := getOwnedFeed(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	renamed, err := s.DB.RenameFeed(context.Background(), database.RenameFeedParams{
+		ID:     feed.ID,
+		UserID: user.ID,
+		Name:   cmd.Args[1],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't rename feed: %w", err)
+	}
+
+	fmt.Printf("%s renamed to %s.\n", feed.Name, renamed.Name)
+	return nil
+}
+
+func feedSetURL(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <feed_url> <new_url>", cmd.Name)
+	}
+
+	feed, err := getOwnedFeed(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.DB.SetFeedURL(context.Background(), database.SetFeedURLParams{
+		ID:     feed.ID,
+		UserID: user.ID,
+		Url:    cmd.Args[1],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't change feed URL: %w", err)
+	}
+
+	fmt.Printf("%s now fetched from %s, starting on the next aggregator run.\n", updated.Name, updated.Url)
+	return nil
+}
+
+func feedDelete(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s <feed_url> [--force]", cmd.Name)
+
+	var feedURL string
+	force := false
+	for _, arg := range cmd.Args {
+		if arg == "--force" {
+			force = true
+		} else if feedURL == "" && !strings.HasPrefix(arg, "--") {
+			feedURL = arg
+		} else {
+			return usage
+		}
+	}
+	if feedURL == "" {
+		return usage
+	}
+
+	feed, err := getOwnedFeed(s, user, feedURL)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a feed unfollows it for everyone, so ask before doing that
+	// to other users
+	followers, err := s.DB.CountFeedFollowers(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't count followers: %w", err)
+	}
+	_, err = s.DB.GetFeedFollowForFeed(context.Background(), database.GetFeedFollowForFeedParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err == nil {
+		followers--
+	}
+	if followers > 0 && !force {
+		return fmt.Errorf("%d other users follow %s, use --force to delete it for them too", followers, feed.Name)
+	}
+
+	_, err = s.DB.DeleteFeed(context.Background(), database.DeleteFeedParams{
+		ID:     feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete feed: %w", err)
+	}
+
+	fmt.Printf("%s deleted, with its posts and fetch history.\n", feed.Name)
+	return nil
+}
+
+func Feed(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %s <enable|log|rename|set-url|delete> [args...]", cmd.Name)
+	}
+
+	subcommand := cmd.Args[0]
+	subArgs := cmd.Args[1:]
+
+	switch subcommand {
+	case "enable":
+		return feedEnable(s, Command{Name: "feed enable", Args: subArgs}, user)
+	case "log":
+		return feedLog(s, Command{Name: "feed log", Args: subArgs}, user)
+	case "rename":
+		return feedRename(s, Command{Name: "feed rename", Args: subArgs}, user)
+	case "set-url":
+		return feedSetURL(s, Command{Name: "feed set-url", Args: subArgs}, user)
+	case "delete":
+		return feedDelete(s, Command{Name: "feed delete", Args: subArgs}, user)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nAvailable: enable, log, rename, set-url, delete", subcommand)
+	}
+}
+
+// getOwnedFeed returns the feed at url if user added it; only they may
+// change or delete it
+func getOwnedFeed(s *State, user database.User, url string) (database.Feed, error) {
+	feed, err := s.DB.GetFeedByURL(context.Background(), url)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("couldn't get feed: %w", err)
+	}
+	if feed.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("%s was added by another user, only they can change it", feed.Name)
+	}
+	return feed, nil
+}
+
 func printFeed(feed database.Feed, user database.User) {
 	fmt.Printf("* ID:            %s\n", feed.ID)
 	fmt.Printf("* Created:       %v\n", feed.CreatedAt)
@@ -81,3 +321,39 @@ func printFeed(feed database.Feed, user database.User) {
 	fmt.Printf("* URL:           %s\n", feed.Url)
 	fmt.Printf("* User:          %s\n", user.Name)
 }
+
+func printFeedHealth(feed database.Feed) {
+	if feed.Disabled {
+		fmt.Printf("* Status:        disabled\n")
+	} else {
+		fmt.Printf("* Status:        failing\n")
+	}
+	fmt.Printf("* Failures:      %d in a row\n", feed.ConsecutiveFailures)
+	if feed.LastError.Valid {
+		fmt.Printf("* Last error:    %s\n", feed.LastError.String)
+	}
+	if feed.LastSuccessAt.Valid {
+		fmt.Printf("* Last success:  %v\n", feed.LastSuccessAt.Time)
+	} else {
+		fmt.Printf("* Last success:  never\n")
+	}
+	if !feed.Disabled && feed.NextFetchAt.Valid {
+		fmt.Printf("* Next attempt:  %v\n", feed.NextFetchAt.Time)
+	}
+}
+
+func printFeedFetch(fetch database.FeedFetch) {
+	fmt.Printf("* Started:       %v\n", fetch.StartedAt)
+	fmt.Printf("* Duration:      %v\n", fetch.FinishedAt.Sub(fetch.StartedAt).Round(time.Millisecond))
+	if fetch.HttpStatus.Valid {
+		fmt.Printf("* HTTP status:   %d\n", fetch.HttpStatus.Int32)
+	}
+	if fetch.Error.Valid {
+		fmt.Printf("* Error:         %s\n", fetch.Error.String)
+		return
+	}
+	fmt.Printf("* Bytes:         %d\n", fetch.Bytes)
+	fmt.Printf("* Items:         %d\n", fetch.ItemCount)
+	fmt.Printf("* New posts:     %d\n", fetch.NewPostCount)
+	fmt.Printf("* Updated posts: %d\n", fetch.UpdatedPostCount)
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,21 +13,62 @@ import (
 )
 
 func Follow(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %s <feed_url>", cmd.Name)
+	if len(cmd.Args) > 0 && cmd.Args[0] == "edit" {
+		return followEdit(s, Command{Name: "follow edit", Args: cmd.Args[1:]}, user)
 	}
 
-	feed, err := s.DB.GetFeedByURL(context.Background(), cmd.Args[0])
+	usage := fmt.Errorf("usage: %s <feed_url> [--folder=name]\n       %s edit <feed_url> [--title=name] [--priority=N] [--mute|--unmute] [--folder=name]", cmd.Name, cmd.Name)
+
+	var feedURL, folderName string
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--folder=") {
+			folderName = strings.TrimPrefix(arg, "--folder=")
+		} else if feedURL == "" && !strings.HasPrefix(arg, "--") {
+			feedURL = arg
+		} else {
+			return usage
+		}
+	}
+	if feedURL == "" {
+		return usage
+	}
+
+	feed, err := s.DB.GetFeedByURL(context.Background(), feedURL)
 	if err != nil {
 		return fmt.Errorf("couldn't get feed: %w", err)
 	}
 
+	folderID := uuid.NullUUID{}
+	if folderName != "" {
+		folder, err := getFolderByName(s, user, folderName)
+		if err != nil {
+			return err
+		}
+		folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+
+		// Following a feed again with --folder moves it to that folder
+		moved, err := s.DB.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			FolderID:  folderID,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't move feed follow: %w", err)
+		}
+		if moved > 0 {
+			fmt.Printf("%s moved to folder %s.\n", feed.Name, folder.Name)
+			return nil
+		}
+	}
+
 	ffRow, err := s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 		UserID:    user.ID,
 		FeedID:    feed.ID,
+		FolderID:  folderID,
 	})
 	if err != nil {
 		return fmt.Errorf("couldn't create feed follow: %w", err)
@@ -32,6 +76,95 @@ func Follow(s *State, cmd Command, user database.User) error {
 
 	fmt.Println("Feed follow created:")
 	printFeedFollow(ffRow.UserName, ffRow.FeedName)
+	if folderName != "" {
+		fmt.Printf("* Folder:        %s\n", folderName)
+	}
+	return nil
+}
+
+// followEdit changes the user's settings for a feed they follow; an empty
+// --title or --folder clears it
+func followEdit(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s <feed_url> [--title=name] [--priority=N] [--mute|--unmute] [--folder=name]", cmd.Name)
+
+	var feedURL string
+	var flags []string
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--") {
+			flags = append(flags, arg)
+		} else if feedURL == "" {
+			feedURL = arg
+		} else {
+			return usage
+		}
+	}
+	if feedURL == "" || len(flags) == 0 {
+		return usage
+	}
+
+	feed, err := s.DB.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+	ff, err := s.DB.GetFeedFollowForFeed(context.Background(), database.GetFeedFollowForFeedParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("you don't follow %s", feed.Url)
+	}
+
+	params := database.UpdateFeedFollowParams{
+		ID:        ff.ID,
+		UserID:    user.ID,
+		Title:     ff.Title,
+		Priority:  ff.Priority,
+		Muted:     ff.Muted,
+		FolderID:  ff.FolderID,
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, arg := range flags {
+		if strings.HasPrefix(arg, "--title=") {
+			title := strings.TrimPrefix(arg, "--title=")
+			params.Title = sql.NullString{String: title, Valid: title != ""}
+		} else if strings.HasPrefix(arg, "--priority=") {
+			priority, err := strconv.Atoi(strings.TrimPrefix(arg, "--priority="))
+			if err != nil {
+				return fmt.Errorf("invalid priority: %w", err)
+			}
+			params.Priority = int32(priority)
+		} else if arg == "--mute" {
+			params.Muted = true
+		} else if arg == "--unmute" {
+			params.Muted = false
+		} else if strings.HasPrefix(arg, "--folder=") {
+			params.FolderID = uuid.NullUUID{}
+			if name := strings.TrimPrefix(arg, "--folder="); name != "" {
+				folder, err := getFolderByName(s, user, name)
+				if err != nil {
+					return err
+				}
+				params.FolderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+			}
+		} else {
+			return usage
+		}
+	}
+
+	ff, err = s.DB.UpdateFeedFollow(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't update feed follow: %w", err)
+	}
+
+	fmt.Println("Feed follow updated:")
+	fmt.Printf("* Feed:          %s\n", feed.Url)
+	if ff.Title.Valid {
+		fmt.Printf("* Title:         %s (feed name: %s)\n", ff.Title.String, feed.Name)
+	} else {
+		fmt.Printf("* Title:         %s\n", feed.Name)
+	}
+	fmt.Printf("* Priority:      %d\n", ff.Priority)
+	fmt.Printf("* Muted:         %t\n", ff.Muted)
 	return nil
 }
 
@@ -47,8 +180,24 @@ func ListFeedFollows(s *State, cmd Command, user database.User) error {
 	}
 
 	fmt.Printf("Feed follows for user %s:\n", user.Name)
+	// Follows arrive ordered by folder, unfiled ones first
+	folder := ""
 	for _, ff := range feedFollows {
-		fmt.Printf("* %s\n", ff.FeedName)
+		if ff.FolderName.String != folder {
+			folder = ff.FolderName.String
+			fmt.Printf("\n%s/\n", folder)
+		}
+		line := "* " + ff.FeedName
+		if folder != "" {
+			line = "  " + line
+		}
+		if ff.Priority != 0 {
+			line += fmt.Sprintf(" (priority %d)", ff.Priority)
+		}
+		if ff.Muted {
+			line += " (muted)"
+		}
+		fmt.Println(line)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mrjacz/gator/internal/database"
+)
+
+// Fetch refreshes a single feed right away, regardless of its schedule, and
+// prints the posts that were new
+func Fetch(s *State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <feed_url|name>", cmd.Name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	feed, err := findFeed(ctx, s.DB, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	if feed.Disabled {
+		return fmt.Errorf("%s is disabled after %d failed fetches (last error: %s), re-enable it with: gator feed enable %s",
+			feed.Name, feed.ConsecutiveFailures, feed.LastError.String, feed.Url)
+	}
+
+	// Take the same lease an aggregator would, so the two never fetch the
+	// feed at once
+	feed, err = s.DB.ClaimFeed(ctx, database.ClaimFeedParams{
+		LeaseSeconds: int32(defaultLease / time.Second),
+		ID:           feed.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s is being fetched by an aggregator right now, try again later", cmd.Args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't claim feed: %w", err)
+	}
+	defer releaseFeedLease(context.WithoutCancel(ctx), s.DB, feed)
+
+	webhooks := newWebhookDispatcher(ctx, s.DB)
+	fetch := scrapeFeed(ctx, s.DB, feed, aggOptions{concurrency: 1, maxFailures: defaultMaxFailures, webhooks: webhooks})
+	// Wait for the new posts to reach their webhooks, retries included
+	webhooks.close()
+	if fetch.Error.Valid {
+		return fmt.Errorf("couldn't fetch %s: %s", feed.Name, fetch.Error.String)
+	}
+
+	duration := time.Since(fetch.StartedAt).Round(time.Millisecond)
+	if fetch.HttpStatus.Int32 == http.StatusNotModified {
+		fmt.Printf("%s not modified since the last fetch (%v).\n", feed.Name, duration)
+		return nil
+	}
+
+	fmt.Printf("Fetched %s in %v: %d items, %d bytes\n", feed.Name, duration, fetch.ItemCount, fetch.Bytes)
+	if fetch.UpdatedPostCount > 0 {
+		fmt.Printf("%d posts updated.\n", fetch.UpdatedPostCount)
+	}
+	if len(fetch.newItems) == 0 {
+		fmt.Println("No new posts.")
+		return nil
+	}
+
+	fmt.Printf("%d new posts:\n", len(fetch.newItems))
+	for _, item := range fetch.newItems {
+		fmt.Printf("* %s\n", item.Title)
+		fmt.Printf("  %s\n", item.Link)
+	}
+	return nil
+}
+
+// findFeed looks a feed up by URL, falling back to its name
+func findFeed(ctx context.Context, db *database.Queries, urlOrName string) (database.Feed, error) {
+	feed, err := db.GetFeedByURL(ctx, urlOrName)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	feeds, err := db.GetFeedsByName(ctx, urlOrName)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("couldn't get feed: %w", err)
+	}
+	switch len(feeds) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("no feed with URL or name %q", urlOrName)
+	case 1:
+		return feeds[0], nil
+	default:
+		return database.Feed{}, fmt.Errorf("%d feeds are named %q, use the feed URL instead", len(feeds), urlOrName)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rss"
+	"github.com/mrjacz/gator/internal/rules"
+)
+
+// loadFilterRules compiles the rules of every user following the feed. It
+// never returns nil, so callers can tell "no rules" from "not loaded yet".
+func loadFilterRules(ctx context.Context, db *database.Queries, feed database.Feed) []*rules.Matcher {
+	matchers := []*rules.Matcher{}
+
+	filterRules, err := db.GetFilterRulesForFeed(ctx, feed.ID)
+	if err != nil {
+		feedLogger(feed).Error("Couldn't get filter rules", "error", err)
+		return matchers
+	}
+
+	for _, rule := range filterRules {
+		matcher, err := rules.Compile(rule)
+		if err != nil {
+			feedLogger(feed).Warn("Skipping invalid filter rule", "rule_id", rule.ID, "user_id", rule.UserID, "error", err)
+			continue
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers
+}
+
+// applyFilterRules runs the matching rules' actions on a newly created post
+// and returns the users who hid it
+func applyFilterRules(ctx context.Context, db *database.Queries, feed database.Feed, postID uuid.UUID, item rss.Item, matchers []*rules.Matcher) map[uuid.UUID]bool {
+	post := rules.Post{
+		Title:       item.Title,
+		Description: item.Description,
+		Content:     item.Content,
+		URL:         item.Link,
+	}
+
+	// Several rules of one user may ask for a bookmark
+	bookmarked := make(map[uuid.UUID]bool)
+	hiddenFor := make(map[uuid.UUID]bool)
+	for _, m := range matchers {
+		if !m.Match(post) {
+			continue
+		}
+
+		rule := m.Rule
+		logger := feedLogger(feed).With("rule_id", rule.ID, "user_id", rule.UserID, "post_id", postID)
+		logger.Info("Filter rule matched", "rule", rules.Describe(rule))
+
+		if rule.Hide {
+			hiddenFor[rule.UserID] = true
+			err := db.HidePost(ctx, database.HidePostParams{
+				RuleID:    rule.ID,
+				PostID:    postID,
+				UserID:    rule.UserID,
+				CreatedAt: time.Now(),
+			})
+			if err != nil {
+				logger.Error("Couldn't hide post", "error", err)
+			}
+		}
+
+		if rule.Bookmark && !bookmarked[rule.UserID] {
+			bookmarked[rule.UserID] = true
+			_, err := db.CreateBookmark(ctx, database.CreateBookmarkParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    rule.UserID,
+				PostID:    postID,
+			})
+			if err != nil {
+				logger.Error("Couldn't bookmark post", "error", err)
+			}
+		}
+
+		if rule.Tag.Valid {
+			err := db.TagPost(ctx, database.TagPostParams{
+				UserID:    rule.UserID,
+				PostID:    postID,
+				Tag:       rule.Tag.String,
+				CreatedAt: time.Now(),
+			})
+			if err != nil {
+				logger.Error("Couldn't tag post", "error", err)
+			}
+		}
+	}
+	return hiddenFor
+}
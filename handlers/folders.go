@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+func folderCreate(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <name>", cmd.Name)
+	}
+
+	folder, err := s.DB.CreateFolder(context.Background(), database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      cmd.Args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create folder: %w", err)
+	}
+
+	fmt.Printf("Folder %s created. Add feeds with: gator follow <feed_url> --folder=%s\n", folder.Name, folder.Name)
+	return nil
+}
+
+func folderList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+
+	folders, err := s.DB.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get folders: %w", err)
+	}
+
+	if len(folders) == 0 {
+		fmt.Println("No folders found. Create one with: gator folder create <name>")
+		return nil
+	}
+
+	fmt.Printf("Folders for user %s:\n", user.Name)
+	for _, folder := range folders {
+		fmt.Printf("* %s (%d feeds)\n", folder.Name, folder.FeedCount)
+	}
+	return nil
+}
+
+func folderRename(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <name> <new_name>", cmd.Name)
+	}
+
+	folder, err := getFolderByName(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	renamed, err := s.DB.RenameFolder(context.Background(), database.RenameFolderParams{
+		ID:        folder.ID,
+		UserID:    user.ID,
+		Name:      cmd.Args[1],
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't rename folder: %w", err)
+	}
+
+	fmt.Printf("Folder %s renamed to %s.\n", folder.Name, renamed.Name)
+	return nil
+}
+
+func folderRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <name>", cmd.Name)
+	}
+
+	folder, err := getFolderByName(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.DeleteFolder(context.Background(), database.DeleteFolderParams{
+		ID:     folder.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete folder: %w", err)
+	}
+
+	fmt.Printf("Folder %s deleted. Its feeds are still followed, outside any folder.\n", folder.Name)
+	return nil
+}
+
+// Folder manages the folders that group the feeds a user follows
+func Folder(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %s <create|list|rename|rm> [args...]", cmd.Name)
+	}
+
+	subcommand := cmd.Args[0]
+	subArgs := cmd.Args[1:]
+
+	switch subcommand {
+	case "create":
+		return folderCreate(s, Command{Name: "folder create", Args: subArgs}, user)
+	case "list":
+		return folderList(s, Command{Name: "folder list", Args: subArgs}, user)
+	case "rename":
+		return folderRename(s, Command{Name: "folder rename", Args: subArgs}, user)
+	case "rm":
+		return folderRemove(s, Command{Name: "folder rm", Args: subArgs}, user)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nAvailable: create, list, rename, rm", subcommand)
+	}
+}
+
+func getFolderByName(s *State, user database.User, name string) (database.Folder, error) {
+	folder, err := s.DB.GetFolderByName(context.Background(), database.GetFolderByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		return database.Folder{}, fmt.Errorf("folder not found: %s", name)
+	}
+	return folder, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+func postHistory(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
+	}
+
+	post, err := findPost(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	revisions, err := s.DB.GetPostRevisions(context.Background(), post.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get post revisions: %w", err)
+	}
+
+	fmt.Printf("Current version (updated %s):\n", post.UpdatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Title: %s\n", post.Title)
+	fmt.Printf("URL: %s\n", post.Url)
+
+	if len(revisions) == 0 {
+		fmt.Println("\nNo earlier versions recorded.")
+		return nil
+	}
+
+	fmt.Printf("\nFound %d earlier version(s):\n", len(revisions))
+
+	// Each revision is the version that was replaced at its created_at, so
+	// compare it against the next newer version to show what changed
+	newer := database.PostRevision{
+		Title:       post.Title,
+		Url:         post.Url,
+		Description: post.Description,
+		Content:     post.Content,
+	}
+	for _, revision := range revisions {
+		fmt.Printf("\n===================\n")
+		fmt.Printf("Replaced: %s\n", revision.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Changed: %s\n", strings.Join(changedPostFields(revision, newer), ", "))
+		fmt.Printf("Title: %s\n", revision.Title)
+		if revision.Url != newer.Url {
+			fmt.Printf("URL: %s\n", revision.Url)
+		}
+		if revision.Description != newer.Description {
+			description := revision.Description
+			if len(description) > 200 {
+				description = description[:200] + "..."
+			}
+			fmt.Printf("Description: %s\n", description)
+		}
+		newer = revision
+	}
+
+	return nil
+}
+
+func changedPostFields(older, newer database.PostRevision) []string {
+	var fields []string
+	if older.Title != newer.Title {
+		fields = append(fields, "title")
+	}
+	if older.Url != newer.Url {
+		fields = append(fields, "url")
+	}
+	if older.Description != newer.Description {
+		fields = append(fields, "description")
+	}
+	if older.Content != newer.Content {
+		fields = append(fields, "content")
+	}
+	return fields
+}
+
+func Post(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %s <history> [args...]", cmd.Name)
+	}
+
+	subcommand := cmd.Args[0]
+	subArgs := cmd.Args[1:]
+
+	switch subcommand {
+	case "history":
+		return postHistory(s, Command{Name: "post history", Args: subArgs}, user)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nAvailable: history", subcommand)
+	}
+}
+
+// findPost looks a post up by ID or URL among the feeds the user follows. A
+// URL that several of those feeds link to is refused rather than guessed.
+func findPost(s *State, user database.User, urlOrID string) (database.Post, error) {
+	if id, err := uuid.Parse(urlOrID); err == nil {
+		post, err := s.DB.GetPostForUser(context.Background(), database.GetPostForUserParams{
+			UserID: user.ID,
+			ID:     id,
+		})
+		if err != nil {
+			return database.Post{}, fmt.Errorf("post not found with ID: %s", urlOrID)
+		}
+		return post, nil
+	}
+
+	posts, err := s.DB.GetPostsForUserByURL(context.Background(), database.GetPostsForUserByURLParams{
+		UserID: user.ID,
+		Url:    urlOrID,
+	})
+	if err != nil {
+		return database.Post{}, fmt.Errorf("couldn't get post: %w", err)
+	}
+	switch len(posts) {
+	case 0:
+		return database.Post{}, fmt.Errorf("post not found with URL: %s", urlOrID)
+	case 1:
+		return posts[0], nil
+	default:
+		ids := make([]string, len(posts))
+		for i, post := range posts {
+			ids[i] = post.ID.String()
+		}
+		return database.Post{}, fmt.Errorf("%d posts link to %s, use one of their IDs instead: %s", len(posts), urlOrID, strings.Join(ids, ", "))
+	}
+}
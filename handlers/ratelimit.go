@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrjacz/gator/internal/config"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+const (
+	// defaultHostRPS and defaultHostBurst apply when the config doesn't set
+	// host_rps and host_burst
+	defaultHostRPS   = 1.0
+	defaultHostBurst = 2
+	// maxLimiterWait is the longest a fetch waits for its turn; feeds whose
+	// host is busier than that are rescheduled instead of holding up the batch
+	maxLimiterWait = time.Minute
+)
+
+// tokenBucket allows rate requests per second with bursts of up to burst.
+// Tokens may go negative: a reservation taken while the bucket is empty is
+// served once the bucket has refilled past zero.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve
+func (b *tokenBucket) cancel() {
+	b.tokens++
+}
+
+type hostLimit struct {
+	bucket *tokenBucket
+	// deferredUntil is set from Retry-After; the host isn't contacted
+	// before then
+	deferredUntil time.Time
+}
+
+// fetchLimiter spaces out requests per host and across all hosts
+type fetchLimiter struct {
+	mu        sync.Mutex
+	global    *tokenBucket // nil when there is no global cap
+	hostRate  float64
+	hostBurst int
+	hosts     map[string]*hostLimit
+}
+
+func newFetchLimiter(cfg config.Config) *fetchLimiter {
+	l := &fetchLimiter{hosts: make(map[string]*hostLimit)}
+	l.configure(cfg)
+	return l
+}
+
+// configure applies the limits from the config, keeping hosts deferred by
+// Retry-After deferred
+func (l *fetchLimiter) configure(cfg config.Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.global = nil
+	if cfg.FetchRPS > 0 {
+		l.global = newTokenBucket(cfg.FetchRPS, 1, now)
+	}
+	l.hostRate = cfg.HostRPS
+	if l.hostRate <= 0 {
+		l.hostRate = defaultHostRPS
+	}
+	l.hostBurst = cfg.HostBurst
+	if l.hostBurst <= 0 {
+		l.hostBurst = defaultHostBurst
+	}
+	for _, h := range l.hosts {
+		h.bucket = newTokenBucket(l.hostRate, l.hostBurst, now)
+	}
+}
+
+// reserve books a request to host. It returns the wait before the request
+// may be sent and true, or, when the host is deferred or the wait would
+// exceed maxLimiterWait, the time until the host is free and false.
+func (l *fetchLimiter) reserve(host string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimit{bucket: newTokenBucket(l.hostRate, l.hostBurst, now)}
+		l.hosts[host] = h
+	}
+	if now.Before(h.deferredUntil) {
+		return h.deferredUntil.Sub(now), false
+	}
+
+	delay := h.bucket.reserve(now)
+	if l.global != nil {
+		delay = max(delay, l.global.reserve(now))
+	}
+	if delay > maxLimiterWait {
+		h.bucket.cancel()
+		if l.global != nil {
+			l.global.cancel()
+		}
+		return delay, false
+	}
+	return delay, true
+}
+
+// deferHost keeps requests away from host for the given duration
+func (l *fetchLimiter) deferHost(host string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimit{bucket: newTokenBucket(l.hostRate, l.hostBurst, time.Now())}
+		l.hosts[host] = h
+	}
+	if until := time.Now().Add(d); until.After(h.deferredUntil) {
+		h.deferredUntil = until
+	}
+}
+
+// waitForHost blocks until the feed's host may be contacted. When that is
+// too far off the feed is rescheduled and false is returned.
+func waitForHost(ctx context.Context, db *database.Queries, feed database.Feed, limiter *fetchLimiter) bool {
+	delay, ok := limiter.reserve(feedHost(feed.Url), time.Now())
+	if !ok {
+		feedLogger(feed).Info("Host is rate limited, deferring feed", "host", feedHost(feed.Url))
+		// Round up so the feed isn't due again before the host is
+		storeNextFetch(ctx, db, feed, (delay + time.Second - 1).Truncate(time.Second))
+		return false
+	}
+	if delay == 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrjacz/gator/internal/config"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		// reservations are taken at these offsets from start, in order
+		at   []time.Duration
+		want []time.Duration
+	}{
+		{
+			name:  "burst is served at once",
+			rate:  1,
+			burst: 3,
+			at:    []time.Duration{0, 0, 0},
+			want:  []time.Duration{0, 0, 0},
+		},
+		{
+			name:  "requests past the burst wait for a refill",
+			rate:  2,
+			burst: 1,
+			at:    []time.Duration{0, 0, 0},
+			want:  []time.Duration{0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name:  "tokens refill over time",
+			rate:  1,
+			burst: 1,
+			at:    []time.Duration{0, time.Second, 1500 * time.Millisecond},
+			want:  []time.Duration{0, 0, 500 * time.Millisecond},
+		},
+		{
+			name:  "refill stops at the burst",
+			rate:  1,
+			burst: 2,
+			at:    []time.Duration{0, time.Hour, time.Hour, time.Hour},
+			want:  []time.Duration{0, 0, 0, time.Second},
+		},
+		{
+			name:  "a clock going backwards doesn't refill",
+			rate:  1,
+			burst: 1,
+			at:    []time.Duration{time.Second, 0},
+			want:  []time.Duration{0, time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := newTokenBucket(tt.rate, tt.burst, start)
+			for i, offset := range tt.at {
+				if got := bucket.reserve(start.Add(offset)); got != tt.want[i] {
+					t.Errorf("reservation %d at +%v waits %v, want %v", i, offset, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(1, 1, now)
+
+	bucket.reserve(now)
+	if got := bucket.reserve(now); got != time.Second {
+		t.Fatalf("second reservation waits %v, want 1s", got)
+	}
+	bucket.cancel()
+	if got := bucket.reserve(now); got != time.Second {
+		t.Errorf("reservation after cancel waits %v, want 1s", got)
+	}
+}
+
+func TestFetchLimiterReserve(t *testing.T) {
+	now := time.Now()
+
+	t.Run("hosts are limited separately", func(t *testing.T) {
+		limiter := newFetchLimiter(config.Config{HostRPS: 1, HostBurst: 1})
+		if delay, ok := limiter.reserve("a.example.com", now); !ok || delay != 0 {
+			t.Errorf("first host = %v, %v, want 0, true", delay, ok)
+		}
+		if delay, ok := limiter.reserve("b.example.com", now); !ok || delay != 0 {
+			t.Errorf("second host = %v, %v, want 0, true", delay, ok)
+		}
+		if delay, ok := limiter.reserve("a.example.com", now); !ok || delay != time.Second {
+			t.Errorf("first host again = %v, %v, want 1s, true", delay, ok)
+		}
+	})
+
+	t.Run("global cap spaces out all hosts", func(t *testing.T) {
+		limiter := newFetchLimiter(config.Config{FetchRPS: 0.5})
+		limiter.reserve("a.example.com", now)
+		if delay, ok := limiter.reserve("b.example.com", now); !ok || delay != 2*time.Second {
+			t.Errorf("second host = %v, %v, want 2s, true", delay, ok)
+		}
+	})
+
+	t.Run("long waits are refused and given back", func(t *testing.T) {
+		limiter := newFetchLimiter(config.Config{HostRPS: 1.0 / 120, HostBurst: 1})
+		limiter.reserve("a.example.com", now)
+		delay, ok := limiter.reserve("a.example.com", now)
+		if ok || delay != 2*time.Minute {
+			t.Errorf("busy host = %v, %v, want 2m, false", delay, ok)
+		}
+		if delay, _ := limiter.reserve("a.example.com", now); delay != 2*time.Minute {
+			t.Errorf("busy host again waits %v, want the refused token given back", delay)
+		}
+	})
+
+	t.Run("deferred hosts are refused", func(t *testing.T) {
+		limiter := newFetchLimiter(config.Config{})
+		limiter.deferHost("a.example.com", time.Hour)
+		delay, ok := limiter.reserve("a.example.com", time.Now())
+		if ok || delay <= 59*time.Minute || delay > time.Hour {
+			t.Errorf("deferred host = %v, %v, want about 1h, false", delay, ok)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+func Read(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
+	}
+
+	post, err := findPost(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post read: %w", err)
+	}
+
+	fmt.Printf("Marked read: %s\n", post.Title)
+	return nil
+}
+
+func Unread(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <post_url|post_id>", cmd.Name)
+	}
+
+	post, err := findPost(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post unread: %w", err)
+	}
+
+	fmt.Printf("Marked unread: %s\n", post.Title)
+	return nil
+}
+
+// MarkAllRead marks all of the user's posts read, or only those of one feed
+// or published before a date
+func MarkAllRead(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [--feed url] [--before YYYY-MM-DD|RFC3339]", cmd.Name)
+
+	params := database.MarkAllPostsReadParams{UserID: user.ID}
+	for i := 0; i < len(cmd.Args); i++ {
+		name, value, ok := strings.Cut(cmd.Args[i], "=")
+		if !ok && i+1 < len(cmd.Args) {
+			i++
+			value = cmd.Args[i]
+		} else if !ok {
+			return usage
+		}
+
+		switch name {
+		case "--feed":
+			feed, err := s.DB.GetFeedByURL(context.Background(), value)
+			if err != nil {
+				return fmt.Errorf("couldn't get feed: %w", err)
+			}
+			params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		case "--before":
+			before, err := parseDate(value)
+			if err != nil {
+				return err
+			}
+			params.Before = sql.NullTime{Time: before, Valid: true}
+		default:
+			return usage
+		}
+	}
+
+	marked, err := s.DB.MarkAllPostsRead(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't mark posts read: %w", err)
+	}
+
+	fmt.Printf("Marked %d posts read.\n", marked)
+	return nil
+}
+
+// parseDate reads a date given on the command line, either a day in local
+// time or an RFC 3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", value)
+	}
+	return t, nil
+}
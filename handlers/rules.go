@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rules"
+)
+
+const defaultRuleTestLimit = 50
+
+func rulesAdd(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [--feed=url] [--field=title|description|content|url|any] <pattern|/regex/> <hide|bookmark|tag=name>...", cmd.Name)
+
+	params := database.CreateFilterRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Field:     rules.FieldAny,
+	}
+	patternSet := false
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--feed=") {
+			feed, err := s.DB.GetFeedByURL(context.Background(), strings.TrimPrefix(arg, "--feed="))
+			if err != nil {
+				return fmt.Errorf("couldn't get feed: %w", err)
+			}
+			params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		} else if strings.HasPrefix(arg, "--field=") {
+			params.Field = strings.TrimPrefix(arg, "--field=")
+		} else if !patternSet {
+			params.Pattern, params.IsRegex = rules.ParsePattern(arg)
+			patternSet = true
+		} else if arg == "hide" {
+			params.Hide = true
+		} else if arg == "bookmark" {
+			params.Bookmark = true
+		} else if strings.HasPrefix(arg, "tag=") && arg != "tag=" {
+			params.Tag = sql.NullString{String: strings.TrimPrefix(arg, "tag="), Valid: true}
+		} else {
+			return usage
+		}
+	}
+	if !patternSet || !(params.Hide || params.Bookmark || params.Tag.Valid) {
+		return usage
+	}
+	if err := rules.Validate(params.Field, params.Pattern, params.IsRegex); err != nil {
+		return err
+	}
+
+	rule, err := s.DB.CreateFilterRule(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't create rule: %w", err)
+	}
+
+	fmt.Println("Rule created:")
+	printRule(s, rule)
+	return nil
+}
+
+func rulesList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+
+	filterRules, err := s.DB.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get rules: %w", err)
+	}
+
+	if len(filterRules) == 0 {
+		fmt.Println("No rules found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d rules:\n", len(filterRules))
+	for _, rule := range filterRules {
+		printRule(s, rule)
+		fmt.Println("=====================================")
+	}
+	return nil
+}
+
+func rulesRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <rule_id>", cmd.Name)
+	}
+
+	ruleID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid rule ID: %w", err)
+	}
+
+	deleted, err := s.DB.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{
+		ID:     ruleID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete rule: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("rule not found: %s", ruleID)
+	}
+
+	fmt.Println("Rule removed. Posts it hid are visible again.")
+	return nil
+}
+
+// rulesTest shows which of the user's recent posts the rules would match,
+// without applying them
+func rulesTest(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [rule_id] [--limit=N]", cmd.Name)
+
+	limit := defaultRuleTestLimit
+	var ruleID uuid.UUID
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--limit=") {
+			parsedLimit, err := strconv.Atoi(strings.TrimPrefix(arg, "--limit="))
+			if err != nil {
+				return fmt.Errorf("invalid limit: %w", err)
+			}
+			if parsedLimit < 1 {
+				return fmt.Errorf("limit must be >= 1")
+			}
+			limit = parsedLimit
+		} else if ruleID == uuid.Nil {
+			parsedID, err := uuid.Parse(arg)
+			if err != nil {
+				return fmt.Errorf("invalid rule ID: %w", err)
+			}
+			ruleID = parsedID
+		} else {
+			return usage
+		}
+	}
+
+	var filterRules []database.FilterRule
+	if ruleID != uuid.Nil {
+		rule, err := s.DB.GetFilterRuleForUser(context.Background(), database.GetFilterRuleForUserParams{
+			ID:     ruleID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get rule: %w", err)
+		}
+		filterRules = []database.FilterRule{rule}
+	} else {
+		var err error
+		filterRules, err = s.DB.GetFilterRulesForUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get rules: %w", err)
+		}
+	}
+	if len(filterRules) == 0 {
+		fmt.Println("No rules to test.")
+		return nil
+	}
+
+	posts, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get posts: %w", err)
+	}
+
+	fmt.Printf("Testing %d rules against your %d most recent posts:\n", len(filterRules), len(posts))
+	for _, rule := range filterRules {
+		matcher, err := rules.Compile(rule)
+		if err != nil {
+			return fmt.Errorf("rule %s is invalid: %w", rule.ID, err)
+		}
+
+		fmt.Println("=====================================")
+		printRule(s, rule)
+		matches := 0
+		for _, post := range posts {
+			if rule.FeedID.Valid && post.FeedID != rule.FeedID.UUID {
+				continue
+			}
+			if !matcher.Match(rules.Post{
+				Title:       post.Title,
+				Description: post.Description,
+				Content:     post.Content,
+				URL:         post.Url,
+			}) {
+				continue
+			}
+			matches++
+			fmt.Printf("  - %s\n", post.Title)
+			fmt.Printf("    %s\n", post.Url)
+		}
+		fmt.Printf("* Matches:       %d\n", matches)
+	}
+	return nil
+}
+
+func Rules(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %s <add|list|rm|test> [args...]", cmd.Name)
+	}
+
+	subcommand := cmd.Args[0]
+	subArgs := cmd.Args[1:]
+
+	switch subcommand {
+	case "add":
+		return rulesAdd(s, Command{Name: "rules add", Args: subArgs}, user)
+	case "list":
+		return rulesList(s, Command{Name: "rules list", Args: subArgs}, user)
+	case "rm":
+		return rulesRemove(s, Command{Name: "rules rm", Args: subArgs}, user)
+	case "test":
+		return rulesTest(s, Command{Name: "rules test", Args: subArgs}, user)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nAvailable: add, list, rm, test", subcommand)
+	}
+}
+
+func printRule(s *State, rule database.FilterRule) {
+	fmt.Printf("* ID:            %s\n", rule.ID)
+	fmt.Printf("* Rule:          %s\n", rules.Describe(rule))
+	if rule.FeedID.Valid {
+		feed, err := s.DB.GetFeedByID(context.Background(), rule.FeedID.UUID)
+		if err == nil {
+			fmt.Printf("* Feed:          %s (%s)\n", feed.Name, feed.Url)
+		}
+	} else {
+		fmt.Printf("* Feed:          all followed feeds\n")
+	}
+}
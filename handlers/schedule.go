@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rss"
+)
+
+const (
+	// minFetchInterval and maxFetchInterval bound the interval derived from
+	// a feed's posting frequency
+	minFetchInterval = 15 * time.Minute
+	maxFetchInterval = 24 * time.Hour
+	// defaultFetchInterval is used until a feed has enough posts to tell
+	// how often it publishes
+	defaultFetchInterval = time.Hour
+	// maxHintedInterval caps publisher hints such as <ttl> and Retry-After
+	maxHintedInterval = 7 * 24 * time.Hour
+	// postingHistorySize is how many recent posts the frequency is taken from
+	postingHistorySize = 20
+	// minRetryInterval is the wait after a first failed fetch; it doubles
+	// with every further failure up to maxFetchInterval
+	minRetryInterval = 5 * time.Minute
+)
+
+// nextFetchDelay decides how long to wait before polling a feed again. The
+// feed is polled about twice per typical gap between its posts, never sooner
+// than the publisher's hints allow, and outside its skipHours/skipDays.
+func nextFetchDelay(now time.Time, schedule rss.Schedule, postDates []time.Time) time.Duration {
+	delay := postingInterval(postDates)
+
+	for _, hint := range []time.Duration{schedule.TTL, schedule.UpdatePeriod, schedule.MaxAge, schedule.RetryAfter} {
+		if hint > delay {
+			delay = min(hint, maxHintedInterval)
+		}
+	}
+
+	return skipBlockedHours(now.Add(delay), schedule).Sub(now)
+}
+
+// postingInterval is half the average gap between the given post dates,
+// which are expected newest first
+func postingInterval(postDates []time.Time) time.Duration {
+	if len(postDates) < 2 {
+		return defaultFetchInterval
+	}
+
+	newest, oldest := postDates[0], postDates[len(postDates)-1]
+	averageGap := newest.Sub(oldest) / time.Duration(len(postDates)-1)
+
+	return max(minFetchInterval, min(averageGap/2, maxFetchInterval))
+}
+
+// backoffDelay is the wait before retrying a feed that failed the given
+// number of times in a row, or the server's Retry-After if that is longer
+func backoffDelay(failures int32, retryAfter time.Duration) time.Duration {
+	delay := minRetryInterval
+	for i := int32(1); i < failures && delay < maxFetchInterval; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxFetchInterval)
+
+	return max(delay, min(retryAfter, maxHintedInterval))
+}
+
+// skipBlockedHours moves t forward to the first hour that is in neither
+// skipHours nor skipDays
+func skipBlockedHours(t time.Time, schedule rss.Schedule) time.Time {
+	if len(schedule.SkipHours) == 0 && len(schedule.SkipDays) == 0 {
+		return t
+	}
+
+	// A week of hours covers every combination; if all of them are
+	// skipped the hints are nonsense and are ignored
+	candidate := t
+	for i := 0; i < 7*24; i++ {
+		utc := candidate.UTC()
+		if !slices.Contains(schedule.SkipHours, utc.Hour()) && !slices.Contains(schedule.SkipDays, utc.Weekday()) {
+			return candidate
+		}
+		candidate = utc.Truncate(time.Hour).Add(time.Hour)
+	}
+	return t
+}
+
+// scheduleNextFetch stores when the feed becomes due again
+func scheduleNextFetch(ctx context.Context, db *database.Queries, feed database.Feed, schedule rss.Schedule) {
+	postDates, err := db.GetRecentPostDates(ctx, database.GetRecentPostDatesParams{
+		FeedID: feed.ID,
+		Limit:  postingHistorySize,
+	})
+	if err != nil {
+		feedLogger(feed).Error("Couldn't get recent posts", "error", err)
+	}
+
+	storeNextFetch(ctx, db, feed, nextFetchDelay(time.Now(), schedule, postDates))
+}
+
+// scheduleRetry backs off a feed whose last fetch failed
+func scheduleRetry(ctx context.Context, db *database.Queries, feed database.Feed, retryAfter time.Duration) {
+	storeNextFetch(ctx, db, feed, backoffDelay(feed.ConsecutiveFailures, retryAfter))
+}
+
+func storeNextFetch(ctx context.Context, db *database.Queries, feed database.Feed, delay time.Duration) {
+	err := db.ScheduleFeedFetch(ctx, database.ScheduleFeedFetchParams{
+		DelaySeconds: int32(delay / time.Second),
+		ID:           feed.ID,
+	})
+	if err != nil {
+		feedLogger(feed).Error("Couldn't schedule next fetch", "error", err)
+		return
+	}
+
+	feedLogger(feed).Info("Next fetch scheduled", "delay", delay.Round(time.Second))
+}
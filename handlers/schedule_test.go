@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrjacz/gator/internal/rss"
+)
+
+// postsEvery returns n post dates, newest first, spaced gap apart
+func postsEvery(now time.Time, gap time.Duration, n int) []time.Time {
+	dates := make([]time.Time, n)
+	for i := range dates {
+		dates[i] = now.Add(-time.Duration(i) * gap)
+	}
+	return dates
+}
+
+func TestNextFetchDelay(t *testing.T) {
+	// A Monday at 10:00 UTC
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		schedule  rss.Schedule
+		postDates []time.Time
+		want      time.Duration
+	}{
+		{
+			name: "too few posts uses the default",
+			want: defaultFetchInterval,
+		},
+		{
+			name:      "half the gap between posts",
+			postDates: postsEvery(now, 4*time.Hour, 5),
+			want:      2 * time.Hour,
+		},
+		{
+			name:      "frequent posts are capped at the minimum",
+			postDates: postsEvery(now, time.Minute, 10),
+			want:      minFetchInterval,
+		},
+		{
+			name:      "rare posts are capped at the maximum",
+			postDates: postsEvery(now, 30*24*time.Hour, 3),
+			want:      maxFetchInterval,
+		},
+		{
+			name:     "a longer ttl wins",
+			schedule: rss.Schedule{TTL: 3 * time.Hour},
+			want:     3 * time.Hour,
+		},
+		{
+			name:      "a shorter hint doesn't shorten the interval",
+			schedule:  rss.Schedule{MaxAge: time.Minute},
+			postDates: postsEvery(now, 4*time.Hour, 5),
+			want:      2 * time.Hour,
+		},
+		{
+			name:     "hints are capped",
+			schedule: rss.Schedule{RetryAfter: 30 * 24 * time.Hour},
+			want:     maxHintedInterval,
+		},
+		{
+			name:     "skipped hours push the fetch to the next open hour",
+			schedule: rss.Schedule{SkipHours: []int{11, 12}},
+			want:     3 * time.Hour,
+		},
+		{
+			name:     "skipped days push the fetch to the next open day",
+			schedule: rss.Schedule{SkipDays: []time.Weekday{time.Monday}},
+			want:     14 * time.Hour,
+		},
+		{
+			name: "skipping every hour is ignored",
+			schedule: rss.Schedule{SkipDays: []time.Weekday{
+				time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+				time.Thursday, time.Friday, time.Saturday,
+			}},
+			want: defaultFetchInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextFetchDelay(now, tt.schedule, tt.postDates); got != tt.want {
+				t.Errorf("nextFetchDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		failures   int32
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{0, 0, minRetryInterval},
+		{1, 0, minRetryInterval},
+		{2, 0, 2 * minRetryInterval},
+		{4, 0, 8 * minRetryInterval},
+		{20, 0, maxFetchInterval},
+		{1000, 0, maxFetchInterval},
+		{1, time.Hour, time.Hour},
+		{4, time.Minute, 8 * minRetryInterval},
+		{1, 30 * 24 * time.Hour, maxHintedInterval},
+	}
+
+	for _, tt := range tests {
+		if got := backoffDelay(tt.failures, tt.retryAfter); got != tt.want {
+			t.Errorf("backoffDelay(%d, %v) = %v, want %v", tt.failures, tt.retryAfter, got, tt.want)
+		}
+	}
+}
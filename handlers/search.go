@@ -47,6 +47,7 @@ func Search(s *State, cmd Command, user database.User) error {
 	for _, post := range posts {
 		fmt.Printf("\n===================\n")
 		fmt.Printf("Title: %s\n", post.Title)
+		fmt.Printf("ID: %s\n", post.ID)
 		fmt.Printf("URL: %s\n", post.Url)
 		fmt.Printf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05"))
 
@@ -2,11 +2,12 @@ package handlers
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
 	"github.com/mrjacz/gator/internal/api"
+	"github.com/mrjacz/gator/internal/metrics"
 )
 
 func Server(s *State, cmd Command) error {
@@ -17,17 +18,15 @@ func Server(s *State, cmd Command) error {
 
 	// Check for JWT secret
 	if os.Getenv("JWT_SECRET") == "" {
-		log.Println("WARNING: JWT_SECRET not set, using default development secret")
-		log.Println("Set JWT_SECRET environment variable for production use")
+		slog.Warn("JWT_SECRET not set, using default development secret; set the JWT_SECRET environment variable for production use")
 	}
 
+	metrics.RegisterFeedCollector(s.DB)
 	server := api.NewServer(s.DB)
 	router := server.SetupRouter()
 
 	addr := fmt.Sprintf(":%s", port)
-	log.Printf("Starting HTTP server on %s", addr)
-	log.Printf("Health check: http://localhost%s/health", addr)
-	log.Printf("API endpoints: http://localhost%s/api", addr)
+	slog.Info("Starting HTTP server", "addr", addr, "url", fmt.Sprintf("http://localhost%s", addr))
 
 	return http.ListenAndServe(addr, router)
 }
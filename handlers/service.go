@@ -20,6 +20,8 @@ Type=simple
 User={{.User}}
 WorkingDirectory={{.WorkingDir}}
 ExecStart={{.ExecPath}} agg {{.Interval}} --concurrency={{.Concurrency}}
+ExecReload=/bin/kill -HUP $MAINPID
+TimeoutStopSec=45
 Restart=always
 RestartSec=10
 StandardOutput=journal
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	blockTagRegexp  = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/h[1-6]|/blockquote|/pre|/tr)\s*/?>`)
+	listItemRegexp  = regexp.MustCompile(`(?i)<\s*li[^>]*>`)
+	anyTagRegexp    = regexp.MustCompile(`<[^>]*>`)
+	blankLineRegexp = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+)
+
+// htmlToText turns an HTML fragment from a feed into plain text suitable for
+// the terminal, keeping paragraph and list breaks
+func htmlToText(s string) string {
+	s = blockTagRegexp.ReplaceAllString(s, "\n")
+	s = listItemRegexp.ReplaceAllString(s, "\n• ")
+	s = anyTagRegexp.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = blankLineRegexp.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// postBody returns the full content of a post, falling back to its
+// description for feeds that only publish a summary
+func postBody(content, description string) string {
+	if content != "" {
+		return htmlToText(content)
+	}
+	return htmlToText(description)
+}
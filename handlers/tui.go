@@ -6,17 +6,31 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
 	"github.com/mrjacz/gator/internal/database"
 )
 
 type tuiModel struct {
-	posts    []database.Post
+	db         *database.Queries
+	userID     uuid.UUID
+	unreadOnly bool
+	// folders are listed in the sidebar after "All feeds"; folder is the
+	// index of the one shown, 0 for all feeds
+	folders []database.GetFoldersForUserRow
+	folder  int
+	posts   []database.Post
+	// read holds the IDs of the posts the user has read
+	read     map[uuid.UUID]bool
 	cursor   int
 	selected map[int]struct{}
 	viewing  bool
+	scroll   int
+	width    int
+	height   int
 	err      error
 }
 
@@ -32,9 +46,18 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241"))
 
+	readStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
 	errorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("196"))
 
+	sidebarStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, true, false, false).
+			BorderForeground(lipgloss.Color("62")).
+			PaddingRight(1).
+			MarginRight(1)
+
 	detailStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("62")).
@@ -45,28 +68,176 @@ func (m tuiModel) Init() tea.Cmd {
 	return nil
 }
 
+// tuiPostLimit is how many posts the TUI lists per folder
+const tuiPostLimit = 20
+
+// postsLoadedMsg carries the posts of the folder picked in the sidebar
+type postsLoadedMsg struct {
+	folder int
+	posts  []database.Post
+	read   map[uuid.UUID]bool
+	err    error
+}
+
+// loadFolder switches to the i-th sidebar entry, loading its posts in the
+// background
+func (m tuiModel) loadFolder(i int) (tuiModel, tea.Cmd) {
+	if i < 0 || i > len(m.folders) || i == m.folder {
+		return m, nil
+	}
+	m.folder = i
+
+	folderName := ""
+	if i > 0 {
+		folderName = m.folders[i-1].Name
+	}
+	return m, func() tea.Msg {
+		posts, read, err := loadTUIPosts(context.Background(), m.db, m.userID, folderName, m.unreadOnly)
+		return postsLoadedMsg{folder: i, posts: posts, read: read, err: err}
+	}
+}
+
+// loadTUIPosts returns the user's latest posts, only those of one folder
+// when folderName is set, and which of them are read
+func loadTUIPosts(ctx context.Context, db *database.Queries, userID uuid.UUID, folderName string, unreadOnly bool) ([]database.Post, map[uuid.UUID]bool, error) {
+	var posts []database.Post
+	var err error
+	if folderName != "" {
+		posts, err = db.GetPostsForUserByFolder(ctx, database.GetPostsForUserByFolderParams{
+			UserID:     userID,
+			FolderName: folderName,
+			UnreadOnly: unreadOnly,
+			Limit:      tuiPostLimit,
+			Offset:     0,
+		})
+	} else {
+		posts, err = db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+			UserID:     userID,
+			UnreadOnly: unreadOnly,
+			Limit:      tuiPostLimit,
+			Offset:     0,
+		})
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get posts: %w", err)
+	}
+
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	readIDs, err := db.GetReadPostIDs(ctx, database.GetReadPostIDsParams{
+		UserID:  userID,
+		PostIds: postIDs,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get read posts: %w", err)
+	}
+	read := make(map[uuid.UUID]bool)
+	for _, id := range readIDs {
+		read[id] = true
+	}
+	return posts, read, nil
+}
+
+// postReadMsg reports whether saving a post's read state failed
+type postReadMsg struct {
+	err error
+}
+
+// setRead marks the post read or unread, saving it in the background
+func (m tuiModel) setRead(post database.Post, read bool) tea.Cmd {
+	if m.read[post.ID] == read {
+		return nil
+	}
+	if read {
+		m.read[post.ID] = true
+	} else {
+		delete(m.read, post.ID)
+	}
+
+	return func() tea.Msg {
+		var err error
+		if read {
+			err = m.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+				UserID: m.userID,
+				PostID: post.ID,
+				ReadAt: time.Now(),
+			})
+		} else {
+			_, err = m.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+				UserID: m.userID,
+				PostID: post.ID,
+			})
+		}
+		return postReadMsg{err: err}
+	}
+}
+
 func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case postsLoadedMsg:
+		// Ignore posts of a folder the user has already moved away from
+		if msg.folder == m.folder {
+			m.posts = msg.posts
+			m.read = msg.read
+			m.err = msg.err
+			m.cursor = 0
+		}
+
+	case postReadMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("couldn't save read state: %w", msg.err)
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
 		case "up", "k":
-			if m.cursor > 0 {
+			if m.viewing {
+				m.scrollBy(-1)
+			} else if m.cursor > 0 {
 				m.cursor--
 			}
 
 		case "down", "j":
-			if m.cursor < len(m.posts)-1 {
+			if m.viewing {
+				m.scrollBy(1)
+			} else if m.cursor < len(m.posts)-1 {
 				m.cursor++
 			}
 
+		case "pgup", "b":
+			if m.viewing {
+				m.scrollBy(-m.bodyHeight())
+			}
+
+		case "pgdown", " ":
+			if m.viewing {
+				m.scrollBy(m.bodyHeight())
+			}
+
 		case "enter":
 			if m.viewing {
 				m.viewing = false
-			} else {
+			} else if len(m.posts) > 0 {
 				m.viewing = true
+				m.scroll = 0
+				// Opening a post marks it read
+				return m, m.setRead(m.posts[m.cursor], true)
+			}
+
+		case "u":
+			// Toggle read/unread
+			if len(m.posts) > 0 && m.cursor < len(m.posts) {
+				post := m.posts[m.cursor]
+				return m, m.setRead(post, !m.read[post.ID])
 			}
 
 		case "o":
@@ -75,6 +246,16 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				openBrowser(m.posts[m.cursor].Url)
 			}
 
+		case "tab", "right", "l":
+			if !m.viewing {
+				return m.loadFolder(m.folder + 1)
+			}
+
+		case "shift+tab", "left", "h":
+			if !m.viewing {
+				return m.loadFolder(m.folder - 1)
+			}
+
 		case "esc":
 			if m.viewing {
 				m.viewing = false
@@ -90,7 +271,7 @@ func (m tuiModel) View() string {
 		return errorStyle.Render(fmt.Sprintf("Error: %v\n\nPress q to quit.", m.err))
 	}
 
-	if len(m.posts) == 0 {
+	if len(m.posts) == 0 && len(m.folders) == 0 {
 		return "No posts found.\n\nPress q to quit."
 	}
 
@@ -107,27 +288,66 @@ func (m tuiModel) renderListView() string {
 	s.WriteString(titleStyle.Render("RSS Posts"))
 	s.WriteString("\n\n")
 
+	if len(m.posts) == 0 {
+		s.WriteString("No posts found.\n")
+	}
 	for i, post := range m.posts {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
 		}
 
-		line := fmt.Sprintf("%s %d. %s", cursor, i+1, post.Title)
+		marker := "•"
+		if m.read[post.ID] {
+			marker = " "
+		}
+
+		line := fmt.Sprintf("%s %s %d. %s", cursor, marker, i+1, post.Title)
 
 		if m.cursor == i {
 			line = selectedStyle.Render(line)
+		} else if m.read[post.ID] {
+			line = readStyle.Render(line)
 		}
 
 		s.WriteString(line)
 		s.WriteString("\n")
 	}
 
-	s.WriteString("\n")
-	s.WriteString(helpStyle.Render("↑/k up • ↓/j down • enter view • o open in browser • q quit"))
-	s.WriteString("\n")
+	help := "↑/k up • ↓/j down • enter view • u toggle read • o open in browser • q quit"
+	if len(m.folders) == 0 {
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render(help))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	list := lipgloss.JoinHorizontal(lipgloss.Top, m.renderSidebar(), s.String())
+	return list + "\n" + helpStyle.Render("tab/→ next folder • shift+tab/← previous folder • "+help) + "\n"
+}
+
+// renderSidebar lists the user's folders, highlighting the one shown
+func (m tuiModel) renderSidebar() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Folders"))
+	s.WriteString("\n\n")
+
+	entries := []string{"All feeds"}
+	for _, folder := range m.folders {
+		entries = append(entries, fmt.Sprintf("%s (%d)", folder.Name, folder.FeedCount))
+	}
+	for i, entry := range entries {
+		if i == m.folder {
+			entry = selectedStyle.Render("> " + entry)
+		} else {
+			entry = "  " + entry
+		}
+		s.WriteString(entry)
+		s.WriteString("\n")
+	}
 
-	return s.String()
+	return sidebarStyle.Render(s.String())
 }
 
 func (m tuiModel) renderDetailView() string {
@@ -140,18 +360,66 @@ func (m tuiModel) renderDetailView() string {
 	content.WriteString(fmt.Sprintf("Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05")))
 	content.WriteString(fmt.Sprintf("URL: %s\n\n", post.Url))
 
-	description := post.Description
-	if len(description) > 500 {
-		description = description[:500] + "..."
+	lines := m.bodyLines()
+	end := m.scroll + m.bodyHeight()
+	if end > len(lines) {
+		end = len(lines)
 	}
-	content.WriteString(description)
+	content.WriteString(strings.Join(lines[m.scroll:end], "\n"))
 	content.WriteString("\n\n")
 
-	content.WriteString(helpStyle.Render("enter/esc back to list • o open in browser • q quit"))
+	help := "↑/k ↓/j scroll • pgup/pgdn page • enter/esc back to list • u toggle read • o open in browser • q quit"
+	if len(lines) > m.bodyHeight() {
+		help = fmt.Sprintf("%d-%d of %d lines • %s", m.scroll+1, end, len(lines), help)
+	}
+	content.WriteString(helpStyle.Render(help))
 
 	return detailStyle.Render(content.String())
 }
 
+// detailChrome is the number of lines the detail view spends on borders,
+// padding, the header and the help line
+const detailChrome = 12
+
+// bodyLines returns the selected post's body wrapped to the terminal width
+func (m tuiModel) bodyLines() []string {
+	post := m.posts[m.cursor]
+	body := postBody(post.Content, post.Description)
+
+	width := m.width - 6 // border and padding
+	if width < 20 {
+		width = 80
+	}
+	wrapped := lipgloss.NewStyle().Width(width).Render(body)
+	return strings.Split(wrapped, "\n")
+}
+
+// bodyHeight is how many body lines fit on screen at once
+func (m tuiModel) bodyHeight() int {
+	if m.height == 0 {
+		return 20 // no WindowSizeMsg yet
+	}
+	if m.height-detailChrome < 3 {
+		return 3
+	}
+	return m.height - detailChrome
+}
+
+func (m *tuiModel) scrollBy(delta int) {
+	maxScroll := len(m.bodyLines()) - m.bodyHeight()
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+
+	m.scroll += delta
+	if m.scroll > maxScroll {
+		m.scroll = maxScroll
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+}
+
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
 
@@ -170,26 +438,36 @@ func openBrowser(url string) error {
 }
 
 func TUI(s *State, cmd Command, user database.User) error {
-	limit := 20
+	unreadOnly := false
 
-	if len(cmd.Args) > 0 {
-		return fmt.Errorf("usage: %s (no arguments)", cmd.Name)
+	for _, arg := range cmd.Args {
+		if arg == "--unread" {
+			unreadOnly = true
+		} else {
+			return fmt.Errorf("usage: %s [--unread]", cmd.Name)
+		}
 	}
 
-	posts, err := s.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-		Offset: 0,
-	})
+	folders, err := s.DB.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get folders: %w", err)
+	}
+
+	posts, read, err := loadTUIPosts(context.Background(), s.DB, user.ID, "", unreadOnly)
 	if err != nil {
-		return fmt.Errorf("couldn't get posts: %w", err)
+		return err
 	}
 
 	initialModel := tuiModel{
-		posts:    posts,
-		cursor:   0,
-		selected: make(map[int]struct{}),
-		viewing:  false,
+		db:         s.DB,
+		userID:     user.ID,
+		unreadOnly: unreadOnly,
+		folders:    folders,
+		posts:      posts,
+		read:       read,
+		cursor:     0,
+		selected:   make(map[int]struct{}),
+		viewing:    false,
 	}
 
 	p := tea.NewProgram(initialModel)
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/metrics"
+	"github.com/mrjacz/gator/internal/rules"
+	"github.com/mrjacz/gator/internal/webhook"
+)
+
+const (
+	webhookWorkers   = 4
+	webhookQueueSize = 100
+	webhookTimeout   = 10 * time.Second
+	// A failed delivery is retried after webhookRetryDelay, doubling after
+	// every attempt, until webhookMaxAttempts have been made
+	webhookMaxAttempts = 5
+	webhookRetryDelay  = 5 * time.Second
+)
+
+// webhookTarget is a webhook with its keyword compiled; keyword is nil when
+// the webhook receives every new post
+type webhookTarget struct {
+	hook    database.Webhook
+	keyword *rules.Matcher
+}
+
+// webhookDelivery is one payload on its way to one webhook
+type webhookDelivery struct {
+	hook    database.Webhook
+	postID  uuid.NullUUID
+	event   string
+	body    []byte
+	attempt int
+}
+
+// webhookDispatcher sends deliveries in the background so that slow or
+// failing receivers never hold up fetching. Retries wait outside the
+// workers, so a receiver that is down only costs a worker per attempt.
+type webhookDispatcher struct {
+	ctx    context.Context
+	db     *database.Queries
+	client *http.Client
+	queue  chan webhookDelivery
+	// pending counts deliveries that haven't succeeded or given up yet,
+	// including those waiting for a retry
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// newWebhookDispatcher starts the delivery workers. Deliveries still pending
+// when ctx is cancelled are dropped.
+func newWebhookDispatcher(ctx context.Context, db *database.Queries) *webhookDispatcher {
+	d := &webhookDispatcher{
+		ctx:    ctx,
+		db:     db,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan webhookDelivery, webhookQueueSize),
+	}
+	for range webhookWorkers {
+		d.workers.Add(1)
+		go d.run()
+	}
+	return d
+}
+
+// send queues a new delivery. When the queue is full the delivery is
+// dropped rather than holding up the fetch that produced it.
+func (d *webhookDispatcher) send(delivery webhookDelivery) {
+	d.pending.Add(1)
+	delivery.attempt = 1
+	select {
+	case d.queue <- delivery:
+	default:
+		webhookLogger(delivery.hook).Warn("Webhook queue full, delivery dropped", "event", delivery.event)
+		metrics.WebhookDropsTotal.WithLabelValues("queue_full").Inc()
+		d.pending.Done()
+	}
+}
+
+// close waits until every delivery has succeeded or given up, then stops
+// the workers
+func (d *webhookDispatcher) close() {
+	d.pending.Wait()
+	close(d.queue)
+	d.workers.Wait()
+}
+
+func (d *webhookDispatcher) push(delivery webhookDelivery) {
+	select {
+	case d.queue <- delivery:
+	case <-d.ctx.Done():
+		d.drop(delivery)
+	}
+}
+
+func (d *webhookDispatcher) drop(delivery webhookDelivery) {
+	webhookLogger(delivery.hook).Warn("Webhook delivery dropped on shutdown", "event", delivery.event, "attempt", delivery.attempt)
+	metrics.WebhookDropsTotal.WithLabelValues("shutdown").Inc()
+	d.pending.Done()
+}
+
+func (d *webhookDispatcher) run() {
+	defer d.workers.Done()
+	for delivery := range d.queue {
+		d.attempt(delivery)
+	}
+}
+
+// attempt sends a delivery once and, if that failed in a way worth
+// retrying, schedules the next attempt
+func (d *webhookDispatcher) attempt(delivery webhookDelivery) {
+	if d.ctx.Err() != nil {
+		d.drop(delivery)
+		return
+	}
+
+	logger := webhookLogger(delivery.hook).With("event", delivery.event, "attempt", delivery.attempt)
+	err := deliverWebhook(d.ctx, d.db, d.client, delivery)
+	if err == nil {
+		logger.Info("Webhook delivered")
+		d.pending.Done()
+		return
+	}
+	if !webhook.Retryable(err) || delivery.attempt >= webhookMaxAttempts {
+		logger.Warn("Webhook delivery failed, giving up", "error", err)
+		d.pending.Done()
+		return
+	}
+
+	delay := webhookRetryDelay << (delivery.attempt - 1)
+	logger.Warn("Webhook delivery failed, retrying", "error", err, "delay", delay)
+	delivery.attempt++
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			d.push(delivery)
+		case <-d.ctx.Done():
+			d.drop(delivery)
+		}
+	}()
+}
+
+// deliverWebhook makes a single delivery attempt and adds it to the
+// webhook's delivery log
+func deliverWebhook(ctx context.Context, db *database.Queries, client *http.Client, delivery webhookDelivery) error {
+	started := time.Now()
+	status, err := webhook.Send(ctx, client, delivery.hook.Url, delivery.hook.Secret, delivery.event, delivery.body)
+	duration := time.Since(started)
+	metrics.ObserveWebhookDelivery(status)
+
+	params := database.CreateWebhookDeliveryParams{
+		ID:         uuid.New(),
+		WebhookID:  delivery.hook.ID,
+		PostID:     delivery.postID,
+		Event:      delivery.event,
+		Attempt:    int32(delivery.attempt),
+		StartedAt:  started,
+		DurationMs: int32(duration.Milliseconds()),
+		StatusCode: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+	}
+	if err != nil {
+		params.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+	// Log the attempt even when it was cut short by a shutdown
+	if _, logErr := db.CreateWebhookDelivery(context.WithoutCancel(ctx), params); logErr != nil {
+		webhookLogger(delivery.hook).Error("Couldn't log webhook delivery", "error", logErr)
+	}
+	return err
+}
+
+// loadWebhooks compiles the webhooks of every user following the feed. Like
+// loadFilterRules it never returns nil.
+func loadWebhooks(ctx context.Context, db *database.Queries, feed database.Feed) []webhookTarget {
+	targets := []webhookTarget{}
+
+	hooks, err := db.GetWebhooksForFeed(ctx, feed.ID)
+	if err != nil {
+		feedLogger(feed).Error("Couldn't get webhooks", "error", err)
+		return targets
+	}
+
+	for _, hook := range hooks {
+		target := webhookTarget{hook: hook}
+		if hook.Keyword.Valid {
+			target.keyword, err = rules.CompileKeyword(hook.Keyword.String, hook.IsRegex)
+			if err != nil {
+				feedLogger(feed).Warn("Skipping webhook with invalid keyword", "webhook_id", hook.ID, "user_id", hook.UserID, "error", err)
+				continue
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// queueWebhooks sends a newly created post to the matching webhooks, except
+// those of users who hid the post with a filter rule
+func queueWebhooks(dispatcher *webhookDispatcher, feed database.Feed, post database.UpsertPostRow, targets []webhookTarget, hiddenFor map[uuid.UUID]bool) {
+	for _, target := range targets {
+		if hiddenFor[target.hook.UserID] {
+			continue
+		}
+		if target.keyword != nil && !target.keyword.Match(rules.Post{
+			Title:       post.Title,
+			Description: post.Description,
+			Content:     post.Content,
+			URL:         post.Url,
+		}) {
+			continue
+		}
+
+		body, err := json.Marshal(webhook.Payload{
+			Event:     webhook.EventPostCreated,
+			WebhookID: target.hook.ID,
+			CreatedAt: time.Now().UTC(),
+			Feed: &webhook.Feed{
+				ID:   feed.ID,
+				Name: feed.Name,
+				URL:  feed.Url,
+			},
+			Post: &webhook.Post{
+				ID:          post.ID,
+				Title:       post.Title,
+				URL:         post.Url,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+			},
+		})
+		if err != nil {
+			webhookLogger(target.hook).Error("Couldn't encode webhook payload", "post_id", post.ID, "error", err)
+			continue
+		}
+
+		dispatcher.send(webhookDelivery{
+			hook:   target.hook,
+			postID: uuid.NullUUID{UUID: post.ID, Valid: true},
+			event:  webhook.EventPostCreated,
+			body:   body,
+		})
+	}
+}
+
+// webhookLogger returns a logger carrying the attributes that identify hook
+func webhookLogger(hook database.Webhook) *slog.Logger {
+	return slog.With("webhook_id", hook.ID, "webhook_url", hook.Url, "user_id", hook.UserID)
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rules"
+	"github.com/mrjacz/gator/internal/webhook"
+)
+
+const defaultWebhookLogLimit = 20
+
+func webhooksAdd(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s <url> [--feed=url] [--keyword=pattern|/regex/] [--secret=secret]", cmd.Name)
+
+	params := database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+	}
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--feed=") {
+			feed, err := s.DB.GetFeedByURL(context.Background(), strings.TrimPrefix(arg, "--feed="))
+			if err != nil {
+				return fmt.Errorf("couldn't get feed: %w", err)
+			}
+			params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		} else if strings.HasPrefix(arg, "--keyword=") {
+			pattern, isRegex := rules.ParsePattern(strings.TrimPrefix(arg, "--keyword="))
+			if err := rules.Validate(rules.FieldAny, pattern, isRegex); err != nil {
+				return err
+			}
+			params.Keyword = sql.NullString{String: pattern, Valid: true}
+			params.IsRegex = isRegex
+		} else if strings.HasPrefix(arg, "--secret=") {
+			params.Secret = strings.TrimPrefix(arg, "--secret=")
+		} else if params.Url == "" && !strings.HasPrefix(arg, "--") {
+			params.Url = arg
+		} else {
+			return usage
+		}
+	}
+	if params.Url == "" {
+		return usage
+	}
+	if err := webhook.ValidateURL(params.Url); err != nil {
+		return err
+	}
+	if params.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return fmt.Errorf("couldn't generate secret: %w", err)
+		}
+		params.Secret = secret
+	}
+
+	hook, err := s.DB.CreateWebhook(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't create webhook: %w", err)
+	}
+
+	fmt.Println("Webhook created:")
+	printWebhook(s, hook)
+	fmt.Printf("* Secret:        %s\n", hook.Secret)
+	fmt.Printf("Verify deliveries by comparing the %s header with the HMAC-SHA256 of the body, keyed with the secret.\n", webhook.SignatureHeader)
+	return nil
+}
+
+func webhooksList(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+
+	hooks, err := s.DB.GetWebhooksForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get webhooks: %w", err)
+	}
+
+	if len(hooks) == 0 {
+		fmt.Println("No webhooks found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d webhooks:\n", len(hooks))
+	for _, hook := range hooks {
+		printWebhook(s, hook)
+		fmt.Println("=====================================")
+	}
+	return nil
+}
+
+func webhooksRemove(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <webhook_id>", cmd.Name)
+	}
+
+	hookID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	deleted, err := s.DB.DeleteWebhook(context.Background(), database.DeleteWebhookParams{
+		ID:     hookID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete webhook: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("webhook not found: %s", hookID)
+	}
+
+	fmt.Println("Webhook removed.")
+	return nil
+}
+
+func webhooksLog(s *State, cmd Command, user database.User) error {
+	usage := fmt.Errorf("usage: %s <webhook_id> [--limit=N]", cmd.Name)
+
+	limit := defaultWebhookLogLimit
+	var hookID uuid.UUID
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--limit=") {
+			parsedLimit, err := strconv.Atoi(strings.TrimPrefix(arg, "--limit="))
+			if err != nil {
+				return fmt.Errorf("invalid limit: %w", err)
+			}
+			if parsedLimit < 1 {
+				return fmt.Errorf("limit must be >= 1")
+			}
+			limit = parsedLimit
+		} else if hookID == uuid.Nil {
+			parsedID, err := uuid.Parse(arg)
+			if err != nil {
+				return fmt.Errorf("invalid webhook ID: %w", err)
+			}
+			hookID = parsedID
+		} else {
+			return usage
+		}
+	}
+	if hookID == uuid.Nil {
+		return usage
+	}
+
+	hook, err := s.DB.GetWebhookForUser(context.Background(), database.GetWebhookForUserParams{
+		ID:     hookID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get webhook: %w", err)
+	}
+
+	deliveries, err := s.DB.GetWebhookDeliveries(context.Background(), database.GetWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get delivery log: %w", err)
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Printf("Nothing has been delivered to %s yet.\n", hook.Url)
+		return nil
+	}
+
+	fmt.Printf("Last %d deliveries to %s:\n", len(deliveries), hook.Url)
+	for _, delivery := range deliveries {
+		fmt.Println("=====================================")
+		printWebhookDelivery(delivery)
+	}
+	return nil
+}
+
+// webhooksTest sends a ping to a webhook right away, without retries
+func webhooksTest(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <webhook_id>", cmd.Name)
+	}
+
+	hookID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	hook, err := s.DB.GetWebhookForUser(context.Background(), database.GetWebhookForUserParams{
+		ID:     hookID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get webhook: %w", err)
+	}
+
+	body, err := json.Marshal(webhook.Payload{
+		Event:     webhook.EventPing,
+		WebhookID: hook.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't encode payload: %w", err)
+	}
+
+	err = deliverWebhook(context.Background(), s.DB, &http.Client{Timeout: webhookTimeout}, webhookDelivery{
+		hook:    hook,
+		event:   webhook.EventPing,
+		body:    body,
+		attempt: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("ping to %s failed: %w", hook.Url, err)
+	}
+
+	fmt.Printf("Ping delivered to %s.\n", hook.Url)
+	return nil
+}
+
+func Webhooks(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return fmt.Errorf("usage: %s <add|list|rm|log|test> [args...]", cmd.Name)
+	}
+
+	subcommand := cmd.Args[0]
+	subArgs := cmd.Args[1:]
+
+	switch subcommand {
+	case "add":
+		return webhooksAdd(s, Command{Name: "webhooks add", Args: subArgs}, user)
+	case "list":
+		return webhooksList(s, Command{Name: "webhooks list", Args: subArgs}, user)
+	case "rm":
+		return webhooksRemove(s, Command{Name: "webhooks rm", Args: subArgs}, user)
+	case "log":
+		return webhooksLog(s, Command{Name: "webhooks log", Args: subArgs}, user)
+	case "test":
+		return webhooksTest(s, Command{Name: "webhooks test", Args: subArgs}, user)
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nAvailable: add, list, rm, log, test", subcommand)
+	}
+}
+
+func printWebhook(s *State, hook database.Webhook) {
+	fmt.Printf("* ID:            %s\n", hook.ID)
+	fmt.Printf("* URL:           %s\n", hook.Url)
+	if hook.FeedID.Valid {
+		feed, err := s.DB.GetFeedByID(context.Background(), hook.FeedID.UUID)
+		if err == nil {
+			fmt.Printf("* Feed:          %s (%s)\n", feed.Name, feed.Url)
+		}
+	} else {
+		fmt.Printf("* Feed:          all followed feeds\n")
+	}
+	if hook.Keyword.Valid && hook.IsRegex {
+		fmt.Printf("* Keyword:       /%s/\n", hook.Keyword.String)
+	} else if hook.Keyword.Valid {
+		fmt.Printf("* Keyword:       %q\n", hook.Keyword.String)
+	}
+}
+
+func printWebhookDelivery(delivery database.WebhookDelivery) {
+	fmt.Printf("* Started:       %v\n", delivery.StartedAt)
+	fmt.Printf("* Event:         %s (attempt %d)\n", delivery.Event, delivery.Attempt)
+	if delivery.PostID.Valid {
+		fmt.Printf("* Post:          %s\n", delivery.PostID.UUID)
+	}
+	fmt.Printf("* Duration:      %v\n", time.Duration(delivery.DurationMs)*time.Millisecond)
+	if delivery.StatusCode.Valid {
+		fmt.Printf("* HTTP status:   %d\n", delivery.StatusCode.Int32)
+	}
+	if delivery.Error.Valid {
+		fmt.Printf("* Error:         %s\n", delivery.Error.String)
+	}
+}
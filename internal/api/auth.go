@@ -87,6 +87,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		setRequestUser(r.Context(), claims.UserID)
 		ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -2,23 +2,44 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
 	"github.com/mrjacz/gator/internal/database"
 )
 
 type FeedResponse struct {
-	ID            uuid.UUID  `json:"id"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Name          string     `json:"name"`
-	URL           string     `json:"url"`
-	UserID        uuid.UUID  `json:"user_id"`
-	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+	ID                  uuid.UUID  `json:"id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	UserID              uuid.UUID  `json:"user_id"`
+	LastFetchedAt       *time.Time `json:"last_fetched_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	Disabled            bool       `json:"disabled"`
+}
+
+type FeedFetchResponse struct {
+	ID               uuid.UUID `json:"id"`
+	FeedID           uuid.UUID `json:"feed_id"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	HTTPStatus       *int32    `json:"http_status,omitempty"`
+	Bytes            int64     `json:"bytes"`
+	ItemCount        int32     `json:"item_count"`
+	NewPostCount     int32     `json:"new_post_count"`
+	UpdatedPostCount int32     `json:"updated_post_count"`
+	Error            string    `json:"error,omitempty"`
 }
 
 type CreateFeedRequest struct {
@@ -26,8 +47,15 @@ type CreateFeedRequest struct {
 	URL  string `json:"url"`
 }
 
+// UpdateFeedRequest changes only the fields it includes
+type UpdateFeedRequest struct {
+	Name *string `json:"name"`
+	URL  *string `json:"url"`
+}
+
 type FollowFeedRequest struct {
-	FeedURL string `json:"feed_url"`
+	FeedURL  string     `json:"feed_url"`
+	FolderID *uuid.UUID `json:"folder_id"`
 }
 
 type FeedFollowResponse struct {
@@ -36,6 +64,24 @@ type FeedFollowResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	UserID    uuid.UUID `json:"user_id"`
 	FeedID    uuid.UUID `json:"feed_id"`
+	// FeedName is the follower's title for the feed when they set one
+	FeedName   string     `json:"feed_name,omitempty"`
+	FeedURL    string     `json:"feed_url,omitempty"`
+	Title      string     `json:"title,omitempty"`
+	Priority   int32      `json:"priority"`
+	Muted      bool       `json:"muted"`
+	FolderID   *uuid.UUID `json:"folder_id,omitempty"`
+	FolderName string     `json:"folder_name,omitempty"`
+}
+
+// UpdateFeedFollowRequest changes only the settings it includes; an empty
+// title clears the override and a null folder_id takes the feed out of its
+// folder
+type UpdateFeedFollowRequest struct {
+	Title    *string         `json:"title"`
+	Priority *int32          `json:"priority"`
+	Muted    *bool           `json:"muted"`
+	FolderID json.RawMessage `json:"folder_id"`
 }
 
 func (s *Server) HandleCreateFeed(w http.ResponseWriter, r *http.Request) {
@@ -94,7 +140,13 @@ func (s *Server) HandleCreateFeed(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) HandleGetFeeds(w http.ResponseWriter, r *http.Request) {
-	feeds, err := s.db.GetFeeds(context.Background())
+	var feeds []database.Feed
+	var err error
+	if r.URL.Query().Get("broken") == "true" {
+		feeds, err = s.db.GetBrokenFeeds(context.Background())
+	} else {
+		feeds, err = s.db.GetFeeds(context.Background())
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feeds")
 		return
@@ -102,23 +154,197 @@ func (s *Server) HandleGetFeeds(w http.ResponseWriter, r *http.Request) {
 
 	feedResponses := make([]FeedResponse, len(feeds))
 	for i, feed := range feeds {
-		var lastFetched *time.Time
-		if feed.LastFetchedAt.Valid {
-			lastFetched = &feed.LastFetchedAt.Time
+		feedResponses[i] = databaseFeedToFeedResponse(feed)
+	}
+
+	respondWithJSON(w, http.StatusOK, feedResponses)
+}
+
+func databaseFeedToFeedResponse(feed database.Feed) FeedResponse {
+	var lastFetched *time.Time
+	if feed.LastFetchedAt.Valid {
+		lastFetched = &feed.LastFetchedAt.Time
+	}
+	var lastSuccess *time.Time
+	if feed.LastSuccessAt.Valid {
+		lastSuccess = &feed.LastSuccessAt.Time
+	}
+
+	return FeedResponse{
+		ID:                  feed.ID,
+		CreatedAt:           feed.CreatedAt,
+		UpdatedAt:           feed.UpdatedAt,
+		Name:                feed.Name,
+		URL:                 feed.Url,
+		UserID:              feed.UserID,
+		LastFetchedAt:       lastFetched,
+		LastSuccessAt:       lastSuccess,
+		LastError:           feed.LastError.String,
+		ConsecutiveFailures: feed.ConsecutiveFailures,
+		Disabled:            feed.Disabled,
+	}
+}
+
+// ownedFeed looks up the feed in the request path, responding with an error
+// unless the user added it
+func (s *Server) ownedFeed(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (database.Feed, bool) {
+	feedID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+		return database.Feed{}, false
+	}
+
+	feed, err := s.db.GetFeedByID(context.Background(), feedID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return database.Feed{}, false
+	}
+	if feed.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "Only the user who added the feed can change it")
+		return database.Feed{}, false
+	}
+	return feed, true
+}
+
+func (s *Server) HandleUpdateFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if (req.Name != nil && *req.Name == "") || (req.URL != nil && *req.URL == "") {
+		respondWithError(w, http.StatusBadRequest, "Name and URL can't be empty")
+		return
+	}
+
+	feed, ok := s.ownedFeed(w, r, userID)
+	if !ok {
+		return
+	}
+
+	params := database.UpdateFeedParams{
+		ID:     feed.ID,
+		UserID: userID,
+	}
+	if req.Name != nil {
+		params.Name = sql.NullString{String: *req.Name, Valid: true}
+	}
+	if req.URL != nil && *req.URL != feed.Url {
+		params.Url = sql.NullString{String: *req.URL, Valid: true}
+	}
+	feed, err = s.db.UpdateFeed(context.Background(), params)
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "A feed with this URL already exists")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFeedToFeedResponse(feed))
+}
+
+func (s *Server) HandleDeleteFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	feed, ok := s.ownedFeed(w, r, userID)
+	if !ok {
+		return
+	}
+
+	// Deleting a feed unfollows it for everyone, so other users' follows
+	// need ?force=true
+	followers, err := s.db.CountFeedFollowers(context.Background(), feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to count followers")
+		return
+	}
+	if _, err := s.db.GetFeedFollowForFeed(context.Background(), database.GetFeedFollowForFeedParams{
+		UserID: userID,
+		FeedID: feed.ID,
+	}); err == nil {
+		followers--
+	}
+	if followers > 0 && r.URL.Query().Get("force") != "true" {
+		respondWithError(w, http.StatusConflict, "Other users follow this feed, add ?force=true to delete it for them too")
+		return
+	}
+
+	_, err = s.db.DeleteFeed(context.Background(), database.DeleteFeedParams{
+		ID:     feed.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Feed deleted successfully"})
+}
+
+func (s *Server) HandleGetFeedFetches(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit := 20
+	if limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
 		}
+	}
+
+	feed, err := s.db.GetFeedByID(context.Background(), feedID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	fetches, err := s.db.GetFeedFetches(context.Background(), database.GetFeedFetchesParams{
+		FeedID: feed.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed log")
+		return
+	}
 
-		feedResponses[i] = FeedResponse{
-			ID:            feed.ID,
-			CreatedAt:     feed.CreatedAt,
-			UpdatedAt:     feed.UpdatedAt,
-			Name:          feed.Name,
-			URL:           feed.Url,
-			UserID:        feed.UserID,
-			LastFetchedAt: lastFetched,
+	responses := make([]FeedFetchResponse, len(fetches))
+	for i, fetch := range fetches {
+		var httpStatus *int32
+		if fetch.HttpStatus.Valid {
+			httpStatus = &fetch.HttpStatus.Int32
+		}
+
+		responses[i] = FeedFetchResponse{
+			ID:               fetch.ID,
+			FeedID:           fetch.FeedID,
+			StartedAt:        fetch.StartedAt,
+			FinishedAt:       fetch.FinishedAt,
+			HTTPStatus:       httpStatus,
+			Bytes:            fetch.Bytes,
+			ItemCount:        fetch.ItemCount,
+			NewPostCount:     fetch.NewPostCount,
+			UpdatedPostCount: fetch.UpdatedPostCount,
+			Error:            fetch.Error.String,
 		}
 	}
 
-	respondWithJSON(w, http.StatusOK, feedResponses)
+	respondWithJSON(w, http.StatusOK, responses)
 }
 
 func (s *Server) HandleFollowFeed(w http.ResponseWriter, r *http.Request) {
@@ -145,12 +371,25 @@ func (s *Server) HandleFollowFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	folderID := uuid.NullUUID{}
+	if req.FolderID != nil {
+		if _, err := s.db.GetFolderForUser(context.Background(), database.GetFolderForUserParams{
+			ID:     *req.FolderID,
+			UserID: userID,
+		}); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Folder not found")
+			return
+		}
+		folderID = uuid.NullUUID{UUID: *req.FolderID, Valid: true}
+	}
+
 	feedFollow, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		UserID:    userID,
 		FeedID:    feed.ID,
+		FolderID:  folderID,
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to follow feed")
@@ -163,6 +402,11 @@ func (s *Server) HandleFollowFeed(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt: feedFollow.UpdatedAt,
 		UserID:    feedFollow.UserID,
 		FeedID:    feedFollow.FeedID,
+		FeedName:  feedFollow.FeedName,
+		FeedURL:   feed.Url,
+		Priority:  feedFollow.Priority,
+		Muted:     feedFollow.Muted,
+		FolderID:  req.FolderID,
 	})
 }
 
@@ -209,14 +453,135 @@ func (s *Server) HandleGetFeedFollows(w http.ResponseWriter, r *http.Request) {
 
 	responses := make([]FeedFollowResponse, len(feedFollows))
 	for i, ff := range feedFollows {
+		var folderID *uuid.UUID
+		if ff.FolderID.Valid {
+			folderID = &ff.FolderID.UUID
+		}
+
 		responses[i] = FeedFollowResponse{
-			ID:        ff.ID,
-			CreatedAt: ff.CreatedAt,
-			UpdatedAt: ff.UpdatedAt,
-			UserID:    ff.UserID,
-			FeedID:    ff.FeedID,
+			ID:         ff.ID,
+			CreatedAt:  ff.CreatedAt,
+			UpdatedAt:  ff.UpdatedAt,
+			UserID:     ff.UserID,
+			FeedID:     ff.FeedID,
+			FeedName:   ff.FeedName,
+			FeedURL:    ff.FeedUrl,
+			Title:      ff.Title.String,
+			Priority:   ff.Priority,
+			Muted:      ff.Muted,
+			FolderID:   folderID,
+			FolderName: ff.FolderName.String,
 		}
 	}
 
 	respondWithJSON(w, http.StatusOK, responses)
 }
+
+func (s *Server) HandleUpdateFeedFollow(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	feedFollowID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed follow ID")
+		return
+	}
+
+	var req UpdateFeedFollowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ff, err := s.db.GetFeedFollowForUser(context.Background(), database.GetFeedFollowForUserParams{
+		ID:     feedFollowID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed follow not found")
+		return
+	}
+
+	params := database.UpdateFeedFollowParams{
+		ID:        ff.ID,
+		UserID:    userID,
+		Title:     ff.Title,
+		Priority:  ff.Priority,
+		Muted:     ff.Muted,
+		FolderID:  ff.FolderID,
+		UpdatedAt: time.Now(),
+	}
+	if req.Title != nil {
+		params.Title = sql.NullString{String: *req.Title, Valid: *req.Title != ""}
+	}
+	if req.Priority != nil {
+		params.Priority = *req.Priority
+	}
+	if req.Muted != nil {
+		params.Muted = *req.Muted
+	}
+	if req.FolderID != nil {
+		var folderID *uuid.UUID
+		if err := json.Unmarshal(req.FolderID, &folderID); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid folder ID")
+			return
+		}
+		params.FolderID = uuid.NullUUID{}
+		if folderID != nil {
+			if _, err := s.db.GetFolderForUser(context.Background(), database.GetFolderForUserParams{
+				ID:     *folderID,
+				UserID: userID,
+			}); err != nil {
+				respondWithError(w, http.StatusBadRequest, "Folder not found")
+				return
+			}
+			params.FolderID = uuid.NullUUID{UUID: *folderID, Valid: true}
+		}
+	}
+
+	feed, err := s.db.GetFeedByID(context.Background(), ff.FeedID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch feed")
+		return
+	}
+
+	ff, err = s.db.UpdateFeedFollow(context.Background(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update feed follow")
+		return
+	}
+
+	var folderID *uuid.UUID
+	if ff.FolderID.Valid {
+		folderID = &ff.FolderID.UUID
+	}
+
+	feedName := feed.Name
+	if ff.Title.Valid {
+		feedName = ff.Title.String
+	}
+
+	respondWithJSON(w, http.StatusOK, FeedFollowResponse{
+		ID:        ff.ID,
+		CreatedAt: ff.CreatedAt,
+		UpdatedAt: ff.UpdatedAt,
+		UserID:    ff.UserID,
+		FeedID:    ff.FeedID,
+		FeedName:  feedName,
+		FeedURL:   feed.Url,
+		Title:     ff.Title.String,
+		Priority:  ff.Priority,
+		Muted:     ff.Muted,
+		FolderID:  folderID,
+	})
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate
+// value, such as a second feed with the same URL
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
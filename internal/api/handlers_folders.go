@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+type FolderResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	FeedCount int64     `json:"feed_count"`
+}
+
+type FolderRequest struct {
+	Name string `json:"name"`
+}
+
+func (s *Server) HandleCreateFolder(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req FolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	folder, err := s.db.CreateFolder(context.Background(), database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		Name:      req.Name,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create folder")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, FolderResponse{
+		ID:        folder.ID,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+		Name:      folder.Name,
+	})
+}
+
+func (s *Server) HandleGetFolders(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	folders, err := s.db.GetFoldersForUser(context.Background(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch folders")
+		return
+	}
+
+	responses := make([]FolderResponse, len(folders))
+	for i, folder := range folders {
+		responses[i] = FolderResponse{
+			ID:        folder.ID,
+			CreatedAt: folder.CreatedAt,
+			UpdatedAt: folder.UpdatedAt,
+			Name:      folder.Name,
+			FeedCount: folder.FeedCount,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+func (s *Server) HandleRenameFolder(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	folderID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid folder ID")
+		return
+	}
+
+	var req FolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	folder, err := s.db.RenameFolder(context.Background(), database.RenameFolderParams{
+		ID:        folderID,
+		UserID:    userID,
+		Name:      req.Name,
+		UpdatedAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Folder not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to rename folder")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, FolderResponse{
+		ID:        folder.ID,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+		Name:      folder.Name,
+	})
+}
+
+func (s *Server) HandleDeleteFolder(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	folderID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid folder ID")
+		return
+	}
+
+	deleted, err := s.db.DeleteFolder(context.Background(), database.DeleteFolderParams{
+		ID:     folderID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete folder")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Folder not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Folder deleted successfully"})
+}
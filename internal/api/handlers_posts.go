@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,14 +15,123 @@ import (
 )
 
 type PostResponse struct {
+	ID          uuid.UUID           `json:"id"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Title       string              `json:"title"`
+	URL         string              `json:"url"`
+	Description string              `json:"description"`
+	PublishedAt time.Time           `json:"published_at"`
+	FeedID      uuid.UUID           `json:"feed_id"`
+	FeedName    string              `json:"feed_name,omitempty"`
+	Content     string              `json:"content,omitempty"`
+	Read        bool                `json:"read"`
+	Enclosures  []EnclosureResponse `json:"enclosures,omitempty"`
+}
+
+type EnclosureResponse struct {
+	URL             string `json:"url"`
+	MimeType        string `json:"mime_type,omitempty"`
+	Length          int64  `json:"length,omitempty"`
+	DurationSeconds *int32 `json:"duration_seconds,omitempty"`
+	Episode         *int32 `json:"episode,omitempty"`
+	ImageURL        string `json:"image_url,omitempty"`
+}
+
+func databaseEnclosureToEnclosureResponse(enclosure database.PostEnclosure) EnclosureResponse {
+	resp := EnclosureResponse{
+		URL:      enclosure.Url,
+		MimeType: enclosure.MimeType,
+		Length:   enclosure.Length,
+		ImageURL: enclosure.ImageUrl.String,
+	}
+	if enclosure.DurationSeconds.Valid {
+		resp.DurationSeconds = &enclosure.DurationSeconds.Int32
+	}
+	if enclosure.Episode.Valid {
+		resp.Episode = &enclosure.Episode.Int32
+	}
+	return resp
+}
+
+// postResponses converts posts for the API and attaches their enclosures
+// and the user's read state with a query each
+func (s *Server) postResponses(ctx context.Context, userID uuid.UUID, posts []database.Post) ([]PostResponse, error) {
+	postResponses := make([]PostResponse, len(posts))
+	if len(posts) == 0 {
+		return postResponses, nil
+	}
+
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	enclosures, err := s.db.GetEnclosuresForPosts(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	enclosuresByPost := make(map[uuid.UUID][]EnclosureResponse)
+	for _, enclosure := range enclosures {
+		enclosuresByPost[enclosure.PostID] = append(enclosuresByPost[enclosure.PostID], databaseEnclosureToEnclosureResponse(enclosure))
+	}
+
+	readIDs, err := s.db.GetReadPostIDs(ctx, database.GetReadPostIDsParams{
+		UserID:  userID,
+		PostIds: postIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	read := make(map[uuid.UUID]bool)
+	for _, id := range readIDs {
+		read[id] = true
+	}
+
+	// Feeds are named as the user titled them
+	feedFollows, err := s.db.GetFeedFollowsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	feedNames := make(map[uuid.UUID]string, len(feedFollows))
+	for _, ff := range feedFollows {
+		feedNames[ff.FeedID] = ff.FeedName
+	}
+
+	for i, post := range posts {
+		postResponses[i] = databasePostToPostResponse(post)
+		postResponses[i].FeedName = feedNames[post.FeedID]
+		postResponses[i].Read = read[post.ID]
+		postResponses[i].Enclosures = enclosuresByPost[post.ID]
+	}
+
+	return postResponses, nil
+}
+
+func databasePostToPostResponse(post database.Post) PostResponse {
+	return PostResponse{
+		ID:          post.ID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description,
+		PublishedAt: post.PublishedAt,
+		FeedID:      post.FeedID,
+		Content:     post.Content,
+	}
+}
+
+type PostRevisionResponse struct {
 	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ReplacedAt  time.Time `json:"replaced_at"`
 	Title       string    `json:"title"`
 	URL         string    `json:"url"`
 	Description string    `json:"description"`
-	PublishedAt time.Time `json:"published_at"`
-	FeedID      uuid.UUID `json:"feed_id"`
+	Content     string    `json:"content,omitempty"`
 }
 
 type BookmarkResponse struct {
@@ -32,8 +143,41 @@ type BookmarkResponse struct {
 	Post      PostResponse `json:"post"`
 }
 
+// CreateBookmarkRequest names the post by post_id, or by post_url when only
+// one followed feed has a post with that URL
 type CreateBookmarkRequest struct {
-	PostURL string `json:"post_url"`
+	PostID  *uuid.UUID `json:"post_id"`
+	PostURL string     `json:"post_url"`
+}
+
+// errAmbiguousPostURL is returned by findPost when posts from several of the
+// user's feeds share the URL
+var errAmbiguousPostURL = errors.New("several posts have this URL")
+
+// findPost looks a post up by ID or URL among the feeds the user follows
+func (s *Server) findPost(ctx context.Context, userID uuid.UUID, urlOrID string) (database.Post, error) {
+	if id, err := uuid.Parse(urlOrID); err == nil {
+		return s.db.GetPostForUser(ctx, database.GetPostForUserParams{
+			UserID: userID,
+			ID:     id,
+		})
+	}
+
+	posts, err := s.db.GetPostsForUserByURL(ctx, database.GetPostsForUserByURLParams{
+		UserID: userID,
+		Url:    urlOrID,
+	})
+	if err != nil {
+		return database.Post{}, err
+	}
+	switch len(posts) {
+	case 0:
+		return database.Post{}, sql.ErrNoRows
+	case 1:
+		return posts[0], nil
+	default:
+		return database.Post{}, errAmbiguousPostURL
+	}
 }
 
 func (s *Server) HandleGetPosts(w http.ResponseWriter, r *http.Request) {
@@ -59,28 +203,23 @@ func (s *Server) HandleGetPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
 	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: userID,
-		Limit:  int32(limit),
-		Offset: int32(offset),
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to fetch posts")
 		return
 	}
 
-	postResponses := make([]PostResponse, len(posts))
-	for i, post := range posts {
-		postResponses[i] = PostResponse{
-			ID:          post.ID,
-			CreatedAt:   post.CreatedAt,
-			UpdatedAt:   post.UpdatedAt,
-			Title:       post.Title,
-			URL:         post.Url,
-			Description: post.Description,
-			PublishedAt: post.PublishedAt,
-			FeedID:      post.FeedID,
-		}
+	postResponses, err := s.postResponses(context.Background(), userID, posts)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch posts")
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, postResponses)
@@ -119,23 +258,58 @@ func (s *Server) HandleSearchPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	postResponses := make([]PostResponse, len(posts))
-	for i, post := range posts {
-		postResponses[i] = PostResponse{
-			ID:          post.ID,
-			CreatedAt:   post.CreatedAt,
-			UpdatedAt:   post.UpdatedAt,
-			Title:       post.Title,
-			URL:         post.Url,
-			Description: post.Description,
-			PublishedAt: post.PublishedAt,
-			FeedID:      post.FeedID,
-		}
+	postResponses, err := s.postResponses(context.Background(), userID, posts)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to search posts")
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, postResponses)
 }
 
+func (s *Server) HandleGetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	postID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	post, err := s.db.GetPostForUser(context.Background(), database.GetPostForUserParams{
+		UserID: userID,
+		ID:     postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	revisions, err := s.db.GetPostRevisions(context.Background(), post.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch post revisions")
+		return
+	}
+
+	revisionResponses := make([]PostRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		revisionResponses[i] = PostRevisionResponse{
+			ID:          revision.ID,
+			ReplacedAt:  revision.CreatedAt,
+			Title:       revision.Title,
+			URL:         revision.Url,
+			Description: revision.Description,
+			Content:     revision.Content,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, revisionResponses)
+}
+
 func (s *Server) HandleCreateBookmark(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserIDFromContext(r.Context())
 	if err != nil {
@@ -149,15 +323,20 @@ func (s *Server) HandleCreateBookmark(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.PostURL == "" {
-		respondWithError(w, http.StatusBadRequest, "Post URL is required")
+	postRef := req.PostURL
+	if req.PostID != nil {
+		postRef = req.PostID.String()
+	}
+	if postRef == "" {
+		respondWithError(w, http.StatusBadRequest, "Post ID or URL is required")
 		return
 	}
 
-	post, err := s.db.GetPostByURL(context.Background(), database.GetPostByURLParams{
-		UserID: userID,
-		Url:    req.PostURL,
-	})
+	post, err := s.findPost(context.Background(), userID, postRef)
+	if errors.Is(err, errAmbiguousPostURL) {
+		respondWithError(w, http.StatusConflict, "Several posts have this URL, use post_id instead")
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Post not found")
 		return
@@ -177,6 +356,12 @@ func (s *Server) HandleCreateBookmark(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	postResponses, err := s.postResponses(context.Background(), userID, []database.Post{post})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch post enclosures")
+		return
+	}
+
 	bookmark, err := s.db.CreateBookmark(context.Background(), database.CreateBookmarkParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
@@ -195,16 +380,7 @@ func (s *Server) HandleCreateBookmark(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt: bookmark.UpdatedAt,
 		UserID:    bookmark.UserID,
 		PostID:    bookmark.PostID,
-		Post: PostResponse{
-			ID:          post.ID,
-			CreatedAt:   post.CreatedAt,
-			UpdatedAt:   post.UpdatedAt,
-			Title:       post.Title,
-			URL:         post.Url,
-			Description: post.Description,
-			PublishedAt: post.PublishedAt,
-			FeedID:      post.FeedID,
-		},
+		Post:      postResponses[0],
 	})
 }
 
@@ -216,12 +392,13 @@ func (s *Server) HandleDeleteBookmark(w http.ResponseWriter, r *http.Request) {
 	}
 
 	vars := mux.Vars(r)
-	postURL := vars["url"]
+	postRef := vars["url"]
 
-	post, err := s.db.GetPostByURL(context.Background(), database.GetPostByURLParams{
-		UserID: userID,
-		Url:    postURL,
-	})
+	post, err := s.findPost(context.Background(), userID, postRef)
+	if errors.Is(err, errAmbiguousPostURL) {
+		respondWithError(w, http.StatusConflict, "Several posts have this URL, use the post ID instead")
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Post not found")
 		return
@@ -263,19 +440,80 @@ func (s *Server) HandleGetBookmarks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	postResponses := make([]PostResponse, len(posts))
-	for i, post := range posts {
-		postResponses[i] = PostResponse{
-			ID:          post.ID,
-			CreatedAt:   post.CreatedAt,
-			UpdatedAt:   post.UpdatedAt,
-			Title:       post.Title,
-			URL:         post.Url,
-			Description: post.Description,
-			PublishedAt: post.PublishedAt,
-			FeedID:      post.FeedID,
-		}
+	postResponses, err := s.postResponses(context.Background(), userID, posts)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch bookmarks")
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, postResponses)
 }
+
+func (s *Server) HandleMarkPostRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	postID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	post, err := s.db.GetPostForUser(context.Background(), database.GetPostForUserParams{
+		UserID: userID,
+		ID:     postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	err = s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		UserID: userID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to mark post read")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Post marked read"})
+}
+
+func (s *Server) HandleMarkPostUnread(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	postID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	post, err := s.db.GetPostForUser(context.Background(), database.GetPostForUserParams{
+		UserID: userID,
+		ID:     postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	_, err = s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+		UserID: userID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to mark post unread")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Post marked unread"})
+}
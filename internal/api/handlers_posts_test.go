@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"github.com/mrjacz/gator/internal/database"
+)
+
+// openTestDB connects to the database named by GATOR_TEST_DB_URL, which must
+// already be migrated with the goose schema in sql/schema. Tests that need a
+// database are skipped when it isn't set.
+func openTestDB(t *testing.T) (*sql.DB, *database.Queries) {
+	t.Helper()
+
+	dbURL := os.Getenv("GATOR_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("GATOR_TEST_DB_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("couldn't open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("couldn't connect to database: %v", err)
+	}
+	return db, database.New(db)
+}
+
+// TestFollowControlsPostVisibility has one user add a feed and another
+// follow and unfollow it, checking every per-user post query and endpoint
+// shows the post only while the second user follows the feed
+func TestFollowControlsPostVisibility(t *testing.T) {
+	db, queries := openTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	suffix := uuid.NewString()
+
+	createUser := func(name string) database.User {
+		t.Helper()
+		user, err := queries.CreateUser(ctx, database.CreateUserParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      name + "-" + suffix,
+		})
+		if err != nil {
+			t.Fatalf("couldn't create user: %v", err)
+		}
+		// Deleting a user cascades to their feeds, follows, folders and tags
+		t.Cleanup(func() {
+			if _, err := db.Exec("DELETE FROM users WHERE id = $1", user.ID); err != nil {
+				t.Errorf("couldn't delete user: %v", err)
+			}
+		})
+		return user
+	}
+	follow := func(user database.User, feed database.Feed, folderID uuid.NullUUID) {
+		t.Helper()
+		_, err := queries.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			FolderID:  folderID,
+		})
+		if err != nil {
+			t.Fatalf("couldn't follow feed: %v", err)
+		}
+	}
+
+	// The follower is created first so that its cleanup runs last, after the
+	// owner's feed and the follower's follows are gone
+	follower := createUser("follower")
+	owner := createUser("owner")
+
+	feed, err := queries.CreateFeed(ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      "Visibility " + suffix,
+		Url:       "https://example.com/" + suffix + "/feed.xml",
+		UserID:    owner.ID,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create feed: %v", err)
+	}
+	follow(owner, feed, uuid.NullUUID{})
+
+	post, err := queries.UpsertPost(ctx, database.UpsertPostParams{
+		FeedID:      feed.ID,
+		Guid:        "https://example.com/" + suffix + "/post",
+		ID:          uuid.New(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Title:       "Post " + suffix,
+		Url:         "https://example.com/" + suffix + "/post",
+		Description: "A post that only shows up while its feed is followed",
+		PublishedAt: now,
+		RevisionID:  uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("couldn't create post: %v", err)
+	}
+
+	folder, err := queries.CreateFolder(ctx, database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    follower.ID,
+		Name:      "Folder " + suffix,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create folder: %v", err)
+	}
+	tag := "tag-" + suffix
+	err = queries.TagPost(ctx, database.TagPostParams{
+		UserID:    follower.ID,
+		PostID:    post.ID,
+		Tag:       tag,
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("couldn't tag post: %v", err)
+	}
+
+	router := NewServer(queries).SetupRouter()
+
+	// listings returns, for each query and endpoint that lists a user's
+	// posts, the IDs of the posts it returned
+	listings := func(user database.User) map[string][]uuid.UUID {
+		t.Helper()
+		listed := make(map[string][]uuid.UUID)
+		add := func(name string, posts []database.Post, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for _, post := range posts {
+				listed[name] = append(listed[name], post.ID)
+			}
+		}
+
+		posts, err := queries.GetPostsForUser(ctx, database.GetPostsForUserParams{
+			UserID: user.ID,
+			Limit:  100,
+		})
+		add("GetPostsForUser", posts, err)
+
+		posts, err = queries.GetPostsForUserSortedByTitle(ctx, database.GetPostsForUserSortedByTitleParams{
+			UserID: user.ID,
+			Limit:  100,
+		})
+		add("GetPostsForUserSortedByTitle", posts, err)
+
+		posts, err = queries.GetPostsForUserByFeed(ctx, database.GetPostsForUserByFeedParams{
+			UserID: user.ID,
+			Url:    feed.Url,
+			Limit:  100,
+		})
+		add("GetPostsForUserByFeed", posts, err)
+
+		posts, err = queries.SearchPostsForUser(ctx, database.SearchPostsForUserParams{
+			UserID: user.ID,
+			Title:  "%" + suffix + "%",
+			Limit:  100,
+		})
+		add("SearchPostsForUser", posts, err)
+
+		posts, err = queries.GetPostsForUserByURL(ctx, database.GetPostsForUserByURLParams{
+			UserID: user.ID,
+			Url:    post.Url,
+		})
+		add("GetPostsForUserByURL", posts, err)
+
+		found, err := queries.GetPostForUser(ctx, database.GetPostForUserParams{
+			UserID: user.ID,
+			ID:     post.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			add("GetPostForUser", nil, nil)
+		} else {
+			add("GetPostForUser", []database.Post{found}, err)
+		}
+
+		digest, err := queries.GetDigestPostsForUser(ctx, database.GetDigestPostsForUserParams{
+			UserID:    user.ID,
+			CreatedAt: now.Add(-time.Minute),
+			Limit:     100,
+		})
+		if err != nil {
+			t.Fatalf("GetDigestPostsForUser: %v", err)
+		}
+		for _, row := range digest {
+			listed["GetDigestPostsForUser"] = append(listed["GetDigestPostsForUser"], row.ID)
+		}
+
+		if user.ID == follower.ID {
+			posts, err = queries.GetPostsForUserByFolder(ctx, database.GetPostsForUserByFolderParams{
+				UserID:     user.ID,
+				FolderName: folder.Name,
+				Limit:      100,
+			})
+			add("GetPostsForUserByFolder", posts, err)
+
+			posts, err = queries.GetPostsForUserByTag(ctx, database.GetPostsForUserByTagParams{
+				UserID: user.ID,
+				Tag:    tag,
+				Limit:  100,
+			})
+			add("GetPostsForUserByTag", posts, err)
+		}
+
+		token, err := GenerateToken(user.ID, user.Name)
+		if err != nil {
+			t.Fatalf("couldn't generate token: %v", err)
+		}
+		for _, path := range []string{"/api/posts?limit=100", "/api/posts/search?limit=100&q=" + suffix} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET %s: status %d: %s", path, rec.Code, rec.Body.String())
+			}
+			var responses []PostResponse
+			if err := json.NewDecoder(rec.Body).Decode(&responses); err != nil {
+				t.Fatalf("GET %s: couldn't decode response: %v", path, err)
+			}
+			name := "GET " + strings.Split(path, "?")[0]
+			listed[name] = nil
+			for _, response := range responses {
+				listed[name] = append(listed[name], response.ID)
+			}
+		}
+
+		return listed
+	}
+
+	// expect checks that every listing of user's posts contains the post
+	// exactly when want is set
+	expect := func(step string, user database.User, want bool) {
+		t.Helper()
+		listed := listings(user)
+		for _, name := range listingNames {
+			// Only the follower has a folder and a tag for the post
+			if user.ID != follower.ID && (name == "GetPostsForUserByFolder" || name == "GetPostsForUserByTag") {
+				continue
+			}
+			got := slices.Contains(listed[name], post.ID)
+			if got != want {
+				t.Errorf("%s: %s lists the post for %s = %v, want %v", step, name, user.Name, got, want)
+			}
+		}
+	}
+
+	expect("before following", follower, false)
+	expect("before following", owner, true)
+
+	follow(follower, feed, uuid.NullUUID{UUID: folder.ID, Valid: true})
+	expect("after following", follower, true)
+	expect("after following", owner, true)
+
+	err = queries.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+		FeedID: feed.ID,
+		UserID: follower.ID,
+	})
+	if err != nil {
+		t.Fatalf("couldn't unfollow feed: %v", err)
+	}
+	expect("after unfollowing", follower, false)
+	expect("after unfollowing", owner, true)
+}
+
+// listingNames are the per-user post queries and endpoints that
+// TestFollowControlsPostVisibility checks
+var listingNames = []string{
+	"GetPostsForUser",
+	"GetPostsForUserSortedByTitle",
+	"GetPostsForUserByFeed",
+	"GetPostsForUserByFolder",
+	"GetPostsForUserByTag",
+	"SearchPostsForUser",
+	"GetPostsForUserByURL",
+	"GetPostForUser",
+	"GetDigestPostsForUser",
+	"GET /api/posts",
+	"GET /api/posts/search",
+}
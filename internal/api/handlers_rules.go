@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rules"
+)
+
+type RuleResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	FeedID    *uuid.UUID `json:"feed_id,omitempty"`
+	Field     string     `json:"field"`
+	Pattern   string     `json:"pattern"`
+	IsRegex   bool       `json:"is_regex"`
+	Hide      bool       `json:"hide"`
+	Bookmark  bool       `json:"bookmark"`
+	Tag       string     `json:"tag,omitempty"`
+}
+
+type RuleRequest struct {
+	FeedID   *uuid.UUID `json:"feed_id"`
+	Field    string     `json:"field"`
+	Pattern  string     `json:"pattern"`
+	IsRegex  bool       `json:"is_regex"`
+	Hide     bool       `json:"hide"`
+	Bookmark bool       `json:"bookmark"`
+	Tag      string     `json:"tag"`
+}
+
+func databaseRuleToRuleResponse(rule database.FilterRule) RuleResponse {
+	var feedID *uuid.UUID
+	if rule.FeedID.Valid {
+		feedID = &rule.FeedID.UUID
+	}
+
+	return RuleResponse{
+		ID:        rule.ID,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+		FeedID:    feedID,
+		Field:     rule.Field,
+		Pattern:   rule.Pattern,
+		IsRegex:   rule.IsRegex,
+		Hide:      rule.Hide,
+		Bookmark:  rule.Bookmark,
+		Tag:       rule.Tag.String,
+	}
+}
+
+// decodeRuleRequest reads and validates a rule from the request body,
+// writing the error response itself when it returns false
+func (s *Server) decodeRuleRequest(w http.ResponseWriter, r *http.Request) (RuleRequest, bool) {
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return req, false
+	}
+
+	if req.Field == "" {
+		req.Field = rules.FieldAny
+	}
+	if err := rules.Validate(req.Field, req.Pattern, req.IsRegex); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	if !req.Hide && !req.Bookmark && req.Tag == "" {
+		respondWithError(w, http.StatusBadRequest, "At least one of hide, bookmark or tag is required")
+		return req, false
+	}
+	if req.FeedID != nil {
+		if _, err := s.db.GetFeedByID(context.Background(), *req.FeedID); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Feed not found")
+			return req, false
+		}
+	}
+
+	return req, true
+}
+
+func (req RuleRequest) feedID() uuid.NullUUID {
+	if req.FeedID == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *req.FeedID, Valid: true}
+}
+
+func (req RuleRequest) tag() sql.NullString {
+	return sql.NullString{String: req.Tag, Valid: req.Tag != ""}
+}
+
+func (s *Server) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	req, ok := s.decodeRuleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := s.db.CreateFilterRule(context.Background(), database.CreateFilterRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		FeedID:    req.feedID(),
+		Field:     req.Field,
+		Pattern:   req.Pattern,
+		IsRegex:   req.IsRegex,
+		Hide:      req.Hide,
+		Bookmark:  req.Bookmark,
+		Tag:       req.tag(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create rule")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseRuleToRuleResponse(rule))
+}
+
+func (s *Server) HandleGetRules(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filterRules, err := s.db.GetFilterRulesForUser(context.Background(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch rules")
+		return
+	}
+
+	responses := make([]RuleResponse, len(filterRules))
+	for i, rule := range filterRules {
+		responses[i] = databaseRuleToRuleResponse(rule)
+	}
+
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+func (s *Server) HandleGetRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ruleID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	rule, err := s.db.GetFilterRuleForUser(context.Background(), database.GetFilterRuleForUserParams{
+		ID:     ruleID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseRuleToRuleResponse(rule))
+}
+
+func (s *Server) HandleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ruleID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	req, ok := s.decodeRuleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := s.db.UpdateFilterRule(context.Background(), database.UpdateFilterRuleParams{
+		ID:       ruleID,
+		UserID:   userID,
+		FeedID:   req.feedID(),
+		Field:    req.Field,
+		Pattern:  req.Pattern,
+		IsRegex:  req.IsRegex,
+		Hide:     req.Hide,
+		Bookmark: req.Bookmark,
+		Tag:      req.tag(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update rule")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseRuleToRuleResponse(rule))
+}
+
+func (s *Server) HandleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	ruleID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	deleted, err := s.db.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{
+		ID:     ruleID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete rule")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Rule deleted successfully"})
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/rules"
+	"github.com/mrjacz/gator/internal/webhook"
+)
+
+type WebhookResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	URL       string     `json:"url"`
+	FeedID    *uuid.UUID `json:"feed_id,omitempty"`
+	Keyword   string     `json:"keyword,omitempty"`
+	IsRegex   bool       `json:"is_regex"`
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookRequest struct {
+	URL     string     `json:"url"`
+	FeedID  *uuid.UUID `json:"feed_id"`
+	Keyword string     `json:"keyword"`
+	IsRegex bool       `json:"is_regex"`
+	Secret  string     `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	WebhookID  uuid.UUID  `json:"webhook_id"`
+	PostID     *uuid.UUID `json:"post_id,omitempty"`
+	Event      string     `json:"event"`
+	Attempt    int32      `json:"attempt"`
+	StartedAt  time.Time  `json:"started_at"`
+	DurationMs int32      `json:"duration_ms"`
+	StatusCode *int32     `json:"status_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+func databaseWebhookToWebhookResponse(hook database.Webhook) WebhookResponse {
+	var feedID *uuid.UUID
+	if hook.FeedID.Valid {
+		feedID = &hook.FeedID.UUID
+	}
+
+	return WebhookResponse{
+		ID:        hook.ID,
+		CreatedAt: hook.CreatedAt,
+		UpdatedAt: hook.UpdatedAt,
+		URL:       hook.Url,
+		FeedID:    feedID,
+		Keyword:   hook.Keyword.String,
+		IsRegex:   hook.IsRegex,
+	}
+}
+
+func (s *Server) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Keyword != "" {
+		if err := rules.Validate(rules.FieldAny, req.Keyword, req.IsRegex); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	feedID := uuid.NullUUID{}
+	if req.FeedID != nil {
+		if _, err := s.db.GetFeedByID(context.Background(), *req.FeedID); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Feed not found")
+			return
+		}
+		feedID = uuid.NullUUID{UUID: *req.FeedID, Valid: true}
+	}
+	if req.Secret == "" {
+		req.Secret, err = webhook.NewSecret()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to generate secret")
+			return
+		}
+	}
+
+	hook, err := s.db.CreateWebhook(context.Background(), database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		Url:       req.URL,
+		Secret:    req.Secret,
+		FeedID:    feedID,
+		Keyword:   sql.NullString{String: req.Keyword, Valid: req.Keyword != ""},
+		IsRegex:   req.IsRegex && req.Keyword != "",
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	response := databaseWebhookToWebhookResponse(hook)
+	response.Secret = hook.Secret
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) HandleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	hooks, err := s.db.GetWebhooksForUser(context.Background(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch webhooks")
+		return
+	}
+
+	responses := make([]WebhookResponse, len(hooks))
+	for i, hook := range hooks {
+		responses[i] = databaseWebhookToWebhookResponse(hook)
+	}
+
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+func (s *Server) HandleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	hookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	hook, err := s.db.GetWebhookForUser(context.Background(), database.GetWebhookForUserParams{
+		ID:     hookID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseWebhookToWebhookResponse(hook))
+}
+
+func (s *Server) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	hookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	deleted, err := s.db.DeleteWebhook(context.Background(), database.DeleteWebhookParams{
+		ID:     hookID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SuccessResponse{Message: "Webhook deleted successfully"})
+}
+
+func (s *Server) HandleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	hookID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit := 20
+	if limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	hook, err := s.db.GetWebhookForUser(context.Background(), database.GetWebhookForUserParams{
+		ID:     hookID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveries(context.Background(), database.GetWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch delivery log")
+		return
+	}
+
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		var postID *uuid.UUID
+		if delivery.PostID.Valid {
+			postID = &delivery.PostID.UUID
+		}
+		var statusCode *int32
+		if delivery.StatusCode.Valid {
+			statusCode = &delivery.StatusCode.Int32
+		}
+
+		responses[i] = WebhookDeliveryResponse{
+			ID:         delivery.ID,
+			WebhookID:  delivery.WebhookID,
+			PostID:     postID,
+			Event:      delivery.Event,
+			Attempt:    delivery.Attempt,
+			StartedAt:  delivery.StartedAt,
+			DurationMs: delivery.DurationMs,
+			StatusCode: statusCode,
+			Error:      delivery.Error.String,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, responses)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const requestInfoKey contextKey = "requestInfo"
+
+// requestInfo collects the attributes of a request that are only known
+// further down the middleware chain, such as the authenticated user
+type requestInfo struct {
+	id     string
+	userID uuid.UUID
+}
+
+// RequestLogMiddleware gives every request an ID, taken from X-Request-ID
+// when the client sends one, and logs the request once it is served
+func RequestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info := &requestInfo{id: r.Header.Get("X-Request-ID")}
+		if info.id == "" {
+			info.id = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", info.id)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		ctx := context.WithValue(r.Context(), requestInfoKey, info)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		attrs := []any{
+			"request_id", info.id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+		}
+		if info.userID != uuid.Nil {
+			attrs = append(attrs, "user_id", info.userID)
+		}
+		slog.Info("API request", attrs...)
+	})
+}
+
+// setRequestUser records the authenticated user for the request log
+func setRequestUser(ctx context.Context, userID uuid.UUID) {
+	if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+		info.userID = userID
+	}
+}
@@ -4,16 +4,22 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/mrjacz/gator/internal/metrics"
 )
 
 func (s *Server) SetupRouter() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(RequestLogMiddleware)
+	r.Use(MetricsMiddleware)
 
 	// Health check
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		respondWithJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	}).Methods("GET")
 
+	// Prometheus metrics
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	// Public routes
 	r.HandleFunc("/api/users", s.HandleCreateUser).Methods("POST")
 	r.HandleFunc("/api/users", s.HandleGetUsers).Methods("GET")
@@ -27,13 +33,40 @@ func (s *Server) SetupRouter() *mux.Router {
 	// Feed routes
 	protected.HandleFunc("/feeds", s.HandleCreateFeed).Methods("POST")
 	protected.HandleFunc("/feeds", s.HandleGetFeeds).Methods("GET")
+	protected.HandleFunc("/feeds/{id}", s.HandleUpdateFeed).Methods("PATCH")
+	protected.HandleFunc("/feeds/{id}", s.HandleDeleteFeed).Methods("DELETE")
+	protected.HandleFunc("/feeds/{id}/fetches", s.HandleGetFeedFetches).Methods("GET")
 	protected.HandleFunc("/feed_follows", s.HandleFollowFeed).Methods("POST")
 	protected.HandleFunc("/feed_follows", s.HandleGetFeedFollows).Methods("GET")
+	protected.HandleFunc("/feed_follows/{id}", s.HandleUpdateFeedFollow).Methods("PATCH")
 	protected.HandleFunc("/feed_follows/{url}", s.HandleUnfollowFeed).Methods("DELETE")
 
+	// Folder routes
+	protected.HandleFunc("/folders", s.HandleCreateFolder).Methods("POST")
+	protected.HandleFunc("/folders", s.HandleGetFolders).Methods("GET")
+	protected.HandleFunc("/folders/{id}", s.HandleRenameFolder).Methods("PATCH")
+	protected.HandleFunc("/folders/{id}", s.HandleDeleteFolder).Methods("DELETE")
+
 	// Post routes
 	protected.HandleFunc("/posts", s.HandleGetPosts).Methods("GET")
 	protected.HandleFunc("/posts/search", s.HandleSearchPosts).Methods("GET")
+	protected.HandleFunc("/posts/{id}/revisions", s.HandleGetPostRevisions).Methods("GET")
+	protected.HandleFunc("/posts/{id}/read", s.HandleMarkPostRead).Methods("PUT")
+	protected.HandleFunc("/posts/{id}/read", s.HandleMarkPostUnread).Methods("DELETE")
+
+	// Filter rule routes
+	protected.HandleFunc("/rules", s.HandleCreateRule).Methods("POST")
+	protected.HandleFunc("/rules", s.HandleGetRules).Methods("GET")
+	protected.HandleFunc("/rules/{id}", s.HandleGetRule).Methods("GET")
+	protected.HandleFunc("/rules/{id}", s.HandleUpdateRule).Methods("PUT")
+	protected.HandleFunc("/rules/{id}", s.HandleDeleteRule).Methods("DELETE")
+
+	// Webhook routes
+	protected.HandleFunc("/webhooks", s.HandleCreateWebhook).Methods("POST")
+	protected.HandleFunc("/webhooks", s.HandleGetWebhooks).Methods("GET")
+	protected.HandleFunc("/webhooks/{id}", s.HandleGetWebhook).Methods("GET")
+	protected.HandleFunc("/webhooks/{id}", s.HandleDeleteWebhook).Methods("DELETE")
+	protected.HandleFunc("/webhooks/{id}/deliveries", s.HandleGetWebhookDeliveries).Methods("GET")
 
 	// Bookmark routes
 	protected.HandleFunc("/bookmarks", s.HandleCreateBookmark).Methods("POST")
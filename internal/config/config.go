@@ -11,6 +11,37 @@ const configFileName = ".gatorconfig.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// FetchRPS caps feed requests per second across all hosts, 0 for no cap
+	FetchRPS float64 `json:"fetch_rps,omitempty"`
+	// HostRPS and HostBurst limit requests to any single host
+	HostRPS   float64 `json:"host_rps,omitempty"`
+	HostBurst int     `json:"host_burst,omitempty"`
+	// SMTP is the mail server digests are sent through
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+	// Digest schedules a daily digest from agg
+	Digest *DigestConfig `json:"digest,omitempty"`
+}
+
+type SMTPConfig struct {
+	Host string `json:"host"`
+	// Port defaults to 587; the connection is upgraded with STARTTLS when
+	// the server offers it
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
+}
+
+type DigestConfig struct {
+	// SendAt is the local time of day, as HH:MM, to send digests at
+	SendAt string `json:"send_at"`
+	// Since is how far back a digest looks, 24h when empty
+	Since string `json:"since,omitempty"`
+	// Format is html or text, html when empty
+	Format string `json:"format,omitempty"`
+	// Recipients maps user names to the address their digest is sent to;
+	// users without one get no digest
+	Recipients map[string]string `json:"recipients"`
 }
 
 func (cfg *Config) SetUser(userName string) error {
@@ -61,7 +61,7 @@ func (q *Queries) DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams)
 }
 
 const getBookmarksForUser = `-- name: GetBookmarksForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
 JOIN bookmarks ON posts.id = bookmarks.post_id
 WHERE bookmarks.user_id = $1
 ORDER BY bookmarks.created_at DESC
@@ -91,6 +91,8 @@ func (q *Queries) GetBookmarksForUser(ctx context.Context, arg GetBookmarksForUs
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
 		); err != nil {
 			return nil, err
 		}
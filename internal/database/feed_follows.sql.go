@@ -7,19 +7,32 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countFeedFollowers = `-- name: CountFeedFollowers :one
+
+SELECT COUNT(*) FROM feed_follows WHERE feed_id = $1
+`
+
+func (q *Queries) CountFeedFollowers(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedFollowers, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES ($1, $2, $3, $4, $5)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder_id)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, title, priority, muted
 )
 SELECT
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder_id, inserted_feed_follow.title, inserted_feed_follow.priority, inserted_feed_follow.muted,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM inserted_feed_follow
@@ -33,6 +46,7 @@ type CreateFeedFollowParams struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
 }
 
 type CreateFeedFollowRow struct {
@@ -41,6 +55,10 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
+	Title     sql.NullString
+	Priority  int32
+	Muted     bool
 	FeedName  string
 	UserName  string
 }
@@ -52,6 +70,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		arg.UpdatedAt,
 		arg.UserID,
 		arg.FeedID,
+		arg.FolderID,
 	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
@@ -60,6 +79,10 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.Priority,
+		&i.Muted,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -81,25 +104,90 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const getFeedFollowForFeed = `-- name: GetFeedFollowForFeed :one
+
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, title, priority, muted FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type GetFeedFollowForFeedParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollowForFeed(ctx context.Context, arg GetFeedFollowForFeedParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForFeed, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.Priority,
+		&i.Muted,
+	)
+	return i, err
+}
+
+const getFeedFollowForUser = `-- name: GetFeedFollowForUser :one
+
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, title, priority, muted FROM feed_follows
+WHERE id = $1 AND user_id = $2
+`
+
+type GetFeedFollowForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollowForUser(ctx context.Context, arg GetFeedFollowForUserParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForUser, arg.ID, arg.UserID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.Priority,
+		&i.Muted,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 
-SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feeds.name AS feed_name, users.name AS user_name
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder_id, feed_follows.title, feed_follows.priority, feed_follows.muted, COALESCE(feed_follows.title, feeds.name)::text AS feed_name, feeds.url AS feed_url, users.name AS user_name, folders.name AS folder_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
+LEFT JOIN folders ON feed_follows.folder_id = folders.id
 WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, feed_name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	FolderID   uuid.NullUUID
+	Title      sql.NullString
+	Priority   int32
+	Muted      bool
+	FeedName   string
+	FeedUrl    string
+	UserName   string
+	FolderName sql.NullString
 }
 
+// feed_name is the follower's title for the feed when they set one
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
 	rows, err := q.db.QueryContext(ctx, getFeedFollowsForUser, userID)
 	if err != nil {
@@ -115,8 +203,14 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
+			&i.Title,
+			&i.Priority,
+			&i.Muted,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
+			&i.FolderName,
 		); err != nil {
 			return nil, err
 		}
@@ -130,3 +224,72 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :execrows
+
+UPDATE feed_follows SET folder_id = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeedFollow = `-- name: UpdateFeedFollow :one
+
+UPDATE feed_follows
+SET title = $3, priority = $4, muted = $5, folder_id = $6, updated_at = $7
+WHERE id = $1 AND user_id = $2
+RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, title, priority, muted
+`
+
+type UpdateFeedFollowParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Title     sql.NullString
+	Priority  int32
+	Muted     bool
+	FolderID  uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollow,
+		arg.ID,
+		arg.UserID,
+		arg.Title,
+		arg.Priority,
+		arg.Muted,
+		arg.FolderID,
+		arg.UpdatedAt,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Title,
+		&i.Priority,
+		&i.Muted,
+	)
+	return i, err
+}
@@ -7,15 +7,115 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimFeed = `-- name: ClaimFeed :one
+UPDATE feeds
+SET lease_expires_at = NOW() + ($1::integer * INTERVAL '1 second')
+WHERE id = $2
+AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type ClaimFeedParams struct {
+	LeaseSeconds int32
+	ID           uuid.UUID
+}
+
+// Leases a single feed whatever its schedule, unless another process holds it
+func (q *Queries) ClaimFeed(ctx context.Context, arg ClaimFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimFeed, arg.LeaseSeconds, arg.ID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET lease_expires_at = NOW() + ($1::integer * INTERVAL '1 second')
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT disabled
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+    ORDER BY (
+        SELECT COALESCE(MAX(feed_follows.priority), 0) FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id
+    ) DESC, next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type ClaimFeedsToFetchParams struct {
+	LeaseSeconds int32
+	MaxFeeds     int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.LeaseSeconds, arg.MaxFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
 `
 
 type CreateFeedParams struct {
@@ -45,17 +145,96 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
 
-const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-WHERE url = $1
+const deleteFeed = `-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1 AND user_id = $2
 `
 
-func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+type DeleteFeedParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteFeed(ctx context.Context, arg DeleteFeedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUnfollowedFeeds = `-- name: DeleteUnfollowedFeeds :many
+DELETE FROM feeds
+WHERE created_at < $1
+AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+// Feeds created before the cutoff that nobody follows; newer ones may be
+// about to get their first follow
+func (q *Queries) DeleteUnfollowedFeeds(ctx context.Context, createdAt time.Time) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, deleteUnfollowedFeeds, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const enableFeed = `-- name: EnableFeed :one
+UPDATE feeds
+SET disabled = FALSE,
+consecutive_failures = 0,
+next_fetch_at = NULL,
+updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, enableFeed, id)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -65,16 +244,26 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
 
-const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at FROM feeds
+WHERE disabled OR consecutive_failures > 0
+ORDER BY disabled DESC, consecutive_failures DESC
 `
 
-func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getFeeds)
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +279,14 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+			&i.LeaseExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -104,14 +301,41 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at FROM feeds
+WHERE url = $1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -121,18 +345,52 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
 
-const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1
+const getFeedStats = `-- name: GetFeedStats :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE disabled) AS disabled,
+    COUNT(*) FILTER (WHERE NOT disabled AND consecutive_failures > 0) AS failing,
+    COUNT(*) FILTER (WHERE NOT disabled AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())) AS due
+FROM feeds
 `
 
-func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+type GetFeedStatsRow struct {
+	Total    int64
+	Disabled int64
+	Failing  int64
+	Due      int64
+}
+
+func (q *Queries) GetFeedStats(ctx context.Context) (GetFeedStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedStats)
+	var i GetFeedStatsRow
+	err := row.Scan(
+		&i.Total,
+		&i.Disabled,
+		&i.Failing,
+		&i.Due,
+	)
+	return i, err
+}
+
+const getFeeds = `-- name: GetFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at FROM feeds
+`
+
+func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeeds)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +406,59 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsByName = `-- name: GetFeedsByName :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at FROM feeds
+WHERE name = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetFeedsByName(ctx context.Context, name string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.Disabled,
+			&i.LeaseExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -167,7 +478,7 @@ UPDATE feeds
 SET last_fetched_at = NOW(),
 updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
 `
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -181,6 +492,250 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, erro
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET last_error = $1,
+consecutive_failures = consecutive_failures + 1,
+disabled = disabled OR ($2::integer > 0 AND consecutive_failures + 1 >= $2::integer)
+WHERE id = $3
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type RecordFeedFailureParams struct {
+	LastError   sql.NullString
+	MaxFailures int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure, arg.LastError, arg.MaxFailures, arg.ID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+consecutive_failures = 0,
+last_success_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :one
+UPDATE feeds
+SET name = $3,
+updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type RenameFeedParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, renameFeed, arg.ID, arg.UserID, arg.Name)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET lease_expires_at = NULL
+WHERE id = $1 AND lease_expires_at = $2
+`
+
+type ReleaseFeedLeaseParams struct {
+	ID             uuid.UUID
+	LeaseExpiresAt sql.NullTime
+}
+
+// Only the holder's own lease is cleared; once it has expired another
+// aggregator may have claimed the feed with a new one
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.ID, arg.LeaseExpiresAt)
+	return err
+}
+
+const scheduleFeedFetch = `-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = NOW() + ($1::integer * INTERVAL '1 second')
+WHERE id = $2
+`
+
+type ScheduleFeedFetchParams struct {
+	DelaySeconds int32
+	ID           uuid.UUID
+}
+
+func (q *Queries) ScheduleFeedFetch(ctx context.Context, arg ScheduleFeedFetchParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleFeedFetch, arg.DelaySeconds, arg.ID)
+	return err
+}
+
+const setFeedURL = `-- name: SetFeedURL :one
+UPDATE feeds
+SET url = $3,
+etag = NULL,
+last_modified = NULL,
+next_fetch_at = NULL,
+last_error = NULL,
+consecutive_failures = 0,
+disabled = FALSE,
+updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type SetFeedURLParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Url    string
+}
+
+// The cached validators, failure count and auto-disable belonged to the old URL
+func (q *Queries) SetFeedURL(ctx context.Context, arg SetFeedURLParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedURL, arg.ID, arg.UserID, arg.Url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET name = COALESCE($1, name),
+url = COALESCE($2, url),
+etag = CASE WHEN $2 IS NULL THEN etag END,
+last_modified = CASE WHEN $2 IS NULL THEN last_modified END,
+next_fetch_at = CASE WHEN $2 IS NULL THEN next_fetch_at END,
+last_error = CASE WHEN $2 IS NULL THEN last_error END,
+consecutive_failures = CASE WHEN $2 IS NULL THEN consecutive_failures ELSE 0 END,
+disabled = disabled AND $2 IS NULL,
+updated_at = NOW()
+WHERE id = $3 AND user_id = $4
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, last_error, consecutive_failures, last_success_at, disabled, lease_expires_at
+`
+
+type UpdateFeedParams struct {
+	Name   sql.NullString
+	Url    sql.NullString
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+// Renames a feed and changes its URL in one statement, leaving out either
+// when it's NULL. A new URL resets the same state as SetFeedURL
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed,
+		arg.Name,
+		arg.Url,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.Disabled,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
+
+const updateFeedValidators = `-- name: UpdateFeedValidators :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1
+`
+
+type UpdateFeedValidatorsParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedValidators(ctx context.Context, arg UpdateFeedValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
@@ -19,14 +19,40 @@ type Bookmark struct {
 	PostID    uuid.UUID
 }
 
+type DigestSend struct {
+	UserID uuid.UUID
+	SentAt time.Time
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	LastError           sql.NullString
+	ConsecutiveFailures int32
+	LastSuccessAt       sql.NullTime
+	Disabled            bool
+	LeaseExpiresAt      sql.NullTime
+}
+
+type FeedFetch struct {
+	ID               uuid.UUID
+	FeedID           uuid.UUID
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	HttpStatus       sql.NullInt32
+	Bytes            int64
+	ItemCount        int32
+	NewPostCount     int32
+	UpdatedPostCount int32
+	Error            sql.NullString
 }
 
 type FeedFollow struct {
@@ -35,6 +61,39 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	FolderID  uuid.NullUUID
+	Title     sql.NullString
+	Priority  int32
+	Muted     bool
+}
+
+type FilterRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	Pattern   string
+	IsRegex   bool
+	Hide      bool
+	Bookmark  bool
+	Tag       sql.NullString
+}
+
+type Folder struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
+type HiddenPost struct {
+	RuleID    uuid.UUID
+	PostID    uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
 }
 
 type Post struct {
@@ -46,6 +105,44 @@ type Post struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Content     string
+	Guid        string
+}
+
+type PostEnclosure struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	PostID          uuid.UUID
+	Url             string
+	MimeType        string
+	Length          int64
+	DurationSeconds sql.NullInt32
+	Episode         sql.NullInt32
+	ImageUrl        sql.NullString
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostRevision struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	PostID      uuid.UUID
+	Title       string
+	Url         string
+	Description string
+	Content     string
+}
+
+type PostTag struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Tag       string
+	CreatedAt time.Time
 }
 
 type User struct {
@@ -54,3 +151,27 @@ type User struct {
 	UpdatedAt time.Time
 	Name      string
 }
+
+type Webhook struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	FeedID    uuid.NullUUID
+	Keyword   sql.NullString
+	IsRegex   bool
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID
+	WebhookID  uuid.UUID
+	PostID     uuid.NullUUID
+	Event      string
+	Attempt    int32
+	StartedAt  time.Time
+	DurationMs int32
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+}
@@ -12,22 +12,54 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7,
-    $8
-)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const adoptPostGUID = `-- name: AdoptPostGUID :execrows
+UPDATE posts
+SET guid = $1
+WHERE posts.feed_id = $2 AND posts.url = $3 AND posts.guid = posts.url
+  AND NOT EXISTS (
+    SELECT 1 FROM posts AS existing
+    WHERE existing.feed_id = $2 AND existing.guid = $1
+  )
+`
+
+type AdoptPostGUIDParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
+}
+
+// Posts stored before items had GUIDs were given their URL as GUID. When the
+// feed supplies a real GUID for the item, move the stored post over to it so
+// the item isn't inserted a second time.
+func (q *Queries) AdoptPostGUID(ctx context.Context, arg AdoptPostGUIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, adoptPostGUID, arg.Guid, arg.FeedID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDigestPostsForUser = `-- name: GetDigestPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid, COALESCE(feed_follows.title, feeds.name)::text AS feed_name, feeds.url AS feed_url FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
+  AND posts.created_at >= $2
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+ORDER BY feed_name ASC, feeds.id, posts.published_at DESC
+LIMIT $3
 `
 
-type CreatePostParams struct {
+type GetDigestPostsForUserParams struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	Limit     int32
+}
+
+type GetDigestPostsForUserRow struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
@@ -36,47 +68,62 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Content     string
+	Guid        string
+	FeedName    string
+	FeedUrl     string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
-		arg.FeedID,
-	)
-	var i Post
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Title,
-		&i.Url,
-		&i.Description,
-		&i.PublishedAt,
-		&i.FeedID,
-	)
-	return i, err
+// Posts stored since the given time, grouped by feed for a digest
+func (q *Queries) GetDigestPostsForUser(ctx context.Context, arg GetDigestPostsForUserParams) ([]GetDigestPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDigestPostsForUser, arg.UserID, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDigestPostsForUserRow
+	for rows.Next() {
+		var i GetDigestPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getPostByURL = `-- name: GetPostByURL :one
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1 AND posts.url = $2
-LIMIT 1
+const getPostForUser = `-- name: GetPostForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2
 `
 
-type GetPostByURLParams struct {
+type GetPostForUserParams struct {
 	UserID uuid.UUID
-	Url    string
+	ID     uuid.UUID
 }
 
-func (q *Queries) GetPostByURL(ctx context.Context, arg GetPostByURLParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, getPostByURL, arg.UserID, arg.Url)
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.UserID, arg.ID)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -87,27 +134,43 @@ func (q *Queries) GetPostByURL(ctx context.Context, arg GetPostByURLParams) (Pos
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.Guid,
 	)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT $2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.published_at DESC
-LIMIT $2
-OFFSET $3
+LIMIT $3
+OFFSET $4
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
-	Offset int32
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Limit      int32
+	Offset     int32
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +187,8 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
 		); err != nil {
 			return nil, err
 		}
@@ -139,25 +204,171 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 }
 
 const getPostsForUserByFeed = `-- name: GetPostsForUserByFeed :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1 AND feeds.url = $2
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND feeds.url = $2
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT $3::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.published_at DESC
-LIMIT $3
-OFFSET $4
+LIMIT $4
+OFFSET $5
 `
 
 type GetPostsForUserByFeedParams struct {
-	UserID uuid.UUID
-	Url    string
-	Limit  int32
-	Offset int32
+	UserID     uuid.UUID
+	Url        string
+	UnreadOnly bool
+	Limit      int32
+	Offset     int32
 }
 
 func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUserByFeedParams) ([]Post, error) {
 	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeed,
 		arg.UserID,
 		arg.Url,
+		arg.UnreadOnly,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFolder = `-- name: GetPostsForUserByFolder :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = $1 AND folders.name = $2
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT $3::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC
+LIMIT $4
+OFFSET $5
+`
+
+type GetPostsForUserByFolderParams struct {
+	UserID     uuid.UUID
+	FolderName string
+	UnreadOnly bool
+	Limit      int32
+	Offset     int32
+}
+
+func (q *Queries) GetPostsForUserByFolder(ctx context.Context, arg GetPostsForUserByFolderParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFolder,
+		arg.UserID,
+		arg.FolderName,
+		arg.UnreadOnly,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByTag = `-- name: GetPostsForUserByTag :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN post_tags ON post_tags.post_id = posts.id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = post_tags.user_id
+WHERE post_tags.user_id = $1 AND post_tags.tag = $2
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT $3::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC
+LIMIT $4
+OFFSET $5
+`
+
+type GetPostsForUserByTagParams struct {
+	UserID     uuid.UUID
+	Tag        string
+	UnreadOnly bool
+	Limit      int32
+	Offset     int32
+}
+
+func (q *Queries) GetPostsForUserByTag(ctx context.Context, arg GetPostsForUserByTagParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByTag,
+		arg.UserID,
+		arg.Tag,
+		arg.UnreadOnly,
 		arg.Limit,
 		arg.Offset,
 	)
@@ -177,6 +388,56 @@ func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUser
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByURL = `-- name: GetPostsForUserByURL :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.url = $2
+ORDER BY posts.published_at DESC
+`
+
+type GetPostsForUserByURLParams struct {
+	UserID uuid.UUID
+	Url    string
+}
+
+// Several feeds may link to the same page, so a URL can match more than one
+// post
+func (q *Queries) GetPostsForUserByURL(ctx context.Context, arg GetPostsForUserByURLParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByURL, arg.UserID, arg.Url)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
 		); err != nil {
 			return nil, err
 		}
@@ -192,22 +453,36 @@ func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUser
 }
 
 const getPostsForUserSortedByTitle = `-- name: GetPostsForUserSortedByTitle :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT $2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.title ASC
-LIMIT $2
-OFFSET $3
+LIMIT $3
+OFFSET $4
 `
 
 type GetPostsForUserSortedByTitleParams struct {
-	UserID uuid.UUID
-	Limit  int32
-	Offset int32
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Limit      int32
+	Offset     int32
 }
 
 func (q *Queries) GetPostsForUserSortedByTitle(ctx context.Context, arg GetPostsForUserSortedByTitleParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUserSortedByTitle, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getPostsForUserSortedByTitle,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -224,6 +499,8 @@ func (q *Queries) GetPostsForUserSortedByTitle(ctx context.Context, arg GetPosts
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
 		); err != nil {
 			return nil, err
 		}
@@ -238,14 +515,53 @@ func (q *Queries) GetPostsForUserSortedByTitle(ctx context.Context, arg GetPosts
 	return items, nil
 }
 
+const getRecentPostDates = `-- name: GetRecentPostDates :many
+SELECT published_at FROM posts
+WHERE feed_id = $1
+ORDER BY published_at DESC
+LIMIT $2
+`
+
+type GetRecentPostDatesParams struct {
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+func (q *Queries) GetRecentPostDates(ctx context.Context, arg GetRecentPostDatesParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostDates, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var published_at time.Time
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchPostsForUser = `-- name: SearchPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
   AND (
     posts.title ILIKE $2
     OR posts.description ILIKE $2
   )
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
 ORDER BY posts.published_at DESC
 LIMIT $3
 `
@@ -274,6 +590,8 @@ func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUser
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
 		); err != nil {
 			return nil, err
 		}
@@ -287,3 +605,104 @@ func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUser
 	}
 	return items, nil
 }
+
+const upsertPost = `-- name: UpsertPost :one
+WITH previous AS (
+    SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid FROM posts
+    WHERE posts.feed_id = $1 AND posts.guid = $2
+), upserted AS (
+    INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid)
+    VALUES (
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $1,
+        $10,
+        $2
+    )
+    ON CONFLICT (feed_id, guid) DO UPDATE
+    SET title = EXCLUDED.title,
+        url = EXCLUDED.url,
+        description = EXCLUDED.description,
+        content = EXCLUDED.content,
+        published_at = EXCLUDED.published_at,
+        updated_at = EXCLUDED.updated_at
+    WHERE (posts.title, posts.url, posts.description, posts.content)
+        IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, EXCLUDED.content)
+    RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid
+), revision AS (
+    INSERT INTO post_revisions (id, created_at, post_id, title, url, description, content)
+    SELECT $11, $5, previous.id, previous.title, previous.url, previous.description, previous.content
+    FROM previous
+    JOIN upserted ON upserted.id = previous.id
+)
+SELECT upserted.id, upserted.created_at, upserted.updated_at, upserted.title, upserted.url, upserted.description, upserted.published_at, upserted.feed_id, upserted.content, upserted.guid, (previous.id IS NULL)::boolean AS inserted
+FROM upserted
+LEFT JOIN previous ON previous.id = upserted.id
+`
+
+type UpsertPostParams struct {
+	FeedID      uuid.UUID
+	Guid        string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	Content     string
+	RevisionID  uuid.UUID
+}
+
+type UpsertPostRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	Content     string
+	Guid        string
+	Inserted    bool
+}
+
+// Inserts a new post, or updates the stored one when the publisher changed
+// it. The version being replaced is kept in post_revisions. Returns no rows
+// when the post is already stored unchanged.
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.FeedID,
+		arg.Guid,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.Content,
+		arg.RevisionID,
+	)
+	var i UpsertPostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.Guid,
+		&i.Inserted,
+	)
+	return i, err
+}
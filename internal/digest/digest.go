@@ -0,0 +1,230 @@
+package digest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mrjacz/gator/internal/config"
+)
+
+// Formats a digest can be rendered in
+const (
+	FormatHTML = "html"
+	FormatText = "text"
+)
+
+const defaultSMTPPort = 587
+
+type Post struct {
+	Title       string
+	URL         string
+	Summary     string
+	PublishedAt time.Time
+}
+
+type Feed struct {
+	Name  string
+	URL   string
+	Posts []Post
+}
+
+// Digest is a summary of the posts a user received between Since and Until,
+// grouped by feed
+type Digest struct {
+	User  string
+	Since time.Time
+	Until time.Time
+	Feeds []Feed
+}
+
+func (d Digest) PostCount() int {
+	n := 0
+	for _, feed := range d.Feeds {
+		n += len(feed.Posts)
+	}
+	return n
+}
+
+func (d Digest) Subject() string {
+	return fmt.Sprintf("Gator digest: %d new posts from %d feeds", d.PostCount(), len(d.Feeds))
+}
+
+const textTemplate = `Gator digest for {{.User}}
+{{.PostCount}} new posts since {{.Since.Format "Mon Jan 2 15:04"}}
+{{range .Feeds}}
+== {{.Name}} ==
+{{range .Posts}}
+* {{.Title}}
+  {{.URL}}
+{{- if .Summary}}
+  {{.Summary}}
+{{- end}}
+{{end}}{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; max-width: 40em;">
+<h1 style="font-size: 1.4em;">Gator digest for {{.User}}</h1>
+<p>{{.PostCount}} new posts since {{.Since.Format "Mon Jan 2 15:04"}}</p>
+{{range .Feeds}}
+<h2 style="font-size: 1.2em; border-bottom: 1px solid #ccc;"><a href="{{.URL}}">{{.Name}}</a></h2>
+<ul>
+{{range .Posts}}<li style="margin-bottom: 0.8em;">
+<a href="{{.URL}}"><strong>{{.Title}}</strong></a>
+<small style="color: #666;">{{.PublishedAt.Format "Jan 2 15:04"}}</small>
+{{if .Summary}}<br>{{.Summary}}{{end}}
+</li>
+{{end}}</ul>
+{{end}}
+</body>
+</html>
+`
+
+var (
+	textTmpl = texttemplate.Must(texttemplate.New("digest").Parse(textTemplate))
+	htmlTmpl = htmltemplate.Must(htmltemplate.New("digest").Parse(htmlTemplate))
+)
+
+// ValidateFormat checks that format is html or text
+func ValidateFormat(format string) error {
+	if format != FormatHTML && format != FormatText {
+		return fmt.Errorf("invalid format %q (must be %s or %s)", format, FormatHTML, FormatText)
+	}
+	return nil
+}
+
+// Render returns the digest as HTML or plain text
+func Render(d Digest, format string) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatHTML:
+		err = htmlTmpl.Execute(&buf, d)
+	case FormatText:
+		err = textTmpl.Execute(&buf, d)
+	default:
+		err = ValidateFormat(format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Message returns the digest as an RFC 5322 email, as written to .eml files
+// and sent over SMTP. HTML digests include the plain text version as an
+// alternative.
+func Message(d Digest, format, from, to string) ([]byte, error) {
+	text, err := Render(d, FormatText)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Subject()))
+	fmt.Fprintf(&buf, "Date: %s\r\n", d.Until.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+
+	if format == FormatText {
+		fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	html, err := Render(d, format)
+	if err != nil {
+		return nil, err
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// Send delivers a message built by Message through the configured SMTP
+// server
+func Send(cfg *config.SMTPConfig, to string, msg []byte) error {
+	if cfg == nil || cfg.Host == "" || cfg.From == "" {
+		return errors.New("smtp is not configured (set smtp.host and smtp.from in the config file)")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid smtp.from address: %w", err)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	return smtp.SendMail(addr, auth, from.Address, []string{recipient.Address}, msg)
+}
+
+// Summarize shortens plain text to at most n runes, cutting at a word
+// boundary where possible
+func Summarize(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrjacz/gator/internal/database"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// FetchesTotal counts feed fetches by HTTP status, or "error" when no
+	// response was received
+	FetchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_feed_fetches_total",
+		Help: "Feed fetches by HTTP status.",
+	}, []string{"status"})
+
+	FetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gator_feed_fetch_duration_seconds",
+		Help:    "Time taken to download and parse a feed.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	})
+
+	PostsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_created_total",
+		Help: "Posts stored for the first time.",
+	})
+
+	PostsUpdatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_updated_total",
+		Help: "Stored posts that changed and were updated.",
+	})
+
+	// DuplicatesSkippedTotal counts feed items already stored unchanged
+	DuplicatesSkippedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_duplicates_skipped_total",
+		Help: "Feed items skipped because they were already stored unchanged.",
+	})
+
+	// ParseFailuresTotal counts feeds that couldn't be parsed (kind "feed")
+	// and items skipped for an unparseable date (kind "date")
+	ParseFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_parse_failures_total",
+		Help: "Feeds and items that couldn't be parsed.",
+	}, []string{"kind"})
+
+	// WebhookDeliveriesTotal counts webhook delivery attempts by HTTP status,
+	// or "error" when no response was received
+	WebhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_webhook_deliveries_total",
+		Help: "Webhook delivery attempts by HTTP status.",
+	}, []string{"status"})
+
+	// WebhookDropsTotal counts webhook deliveries given up without being
+	// attempted, because the queue was full ("queue_full") or the process
+	// was stopping ("shutdown")
+	WebhookDropsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_webhook_deliveries_dropped_total",
+		Help: "Webhook deliveries dropped before being sent.",
+	}, []string{"reason"})
+
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gator_api_request_duration_seconds",
+		Help:    "API request latency by route, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+)
+
+// feedCollector reports the feed gauges, read from the database on every
+// scrape so that all aggregators and the server agree
+type feedCollector struct {
+	db       *database.Queries
+	feeds    *prometheus.Desc
+	disabled *prometheus.Desc
+	failing  *prometheus.Desc
+	due      *prometheus.Desc
+}
+
+// RegisterFeedCollector adds the feed count and backlog gauges to the
+// default registry
+func RegisterFeedCollector(db *database.Queries) {
+	prometheus.MustRegister(&feedCollector{
+		db:       db,
+		feeds:    prometheus.NewDesc("gator_feeds", "Feeds known to gator.", nil, nil),
+		disabled: prometheus.NewDesc("gator_feeds_disabled", "Feeds disabled after repeated failures.", nil, nil),
+		failing:  prometheus.NewDesc("gator_feeds_failing", "Enabled feeds whose last fetch failed.", nil, nil),
+		due:      prometheus.NewDesc("gator_feeds_due", "Enabled feeds due for fetching (the backlog).", nil, nil),
+	})
+}
+
+func (c *feedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.feeds
+	ch <- c.disabled
+	ch <- c.failing
+	ch <- c.due
+}
+
+func (c *feedCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := c.db.GetFeedStats(ctx)
+	if err != nil {
+		slog.Error("Couldn't collect feed metrics", "error", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.feeds, prometheus.GaugeValue, float64(stats.Total))
+	ch <- prometheus.MustNewConstMetric(c.disabled, prometheus.GaugeValue, float64(stats.Disabled))
+	ch <- prometheus.MustNewConstMetric(c.failing, prometheus.GaugeValue, float64(stats.Failing))
+	ch <- prometheus.MustNewConstMetric(c.due, prometheus.GaugeValue, float64(stats.Due))
+}
+
+// Handler serves the default registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Listen serves /metrics on addr in the background until ctx is cancelled
+func Listen(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	context.AfterFunc(ctx, func() {
+		srv.Close()
+	})
+	return nil
+}
+
+// ObserveFetch records the outcome of a feed fetch. status is 0 when no
+// HTTP response was received.
+func ObserveFetch(status int, duration time.Duration) {
+	FetchesTotal.WithLabelValues(statusLabel(status)).Inc()
+	FetchDuration.Observe(duration.Seconds())
+}
+
+// ObserveWebhookDelivery records a webhook delivery attempt. status is 0 when
+// no HTTP response was received.
+func ObserveWebhookDelivery(status int) {
+	WebhookDeliveriesTotal.WithLabelValues(statusLabel(status)).Inc()
+}
+
+func statusLabel(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,129 @@
+package rss
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomFeed struct {
+	Title    AtomText    `xml:"title"`
+	Subtitle AtomText    `xml:"subtitle"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string       `xml:"id"`
+	Title     AtomText     `xml:"title"`
+	Link      []AtomLink   `xml:"link"`
+	Summary   AtomText     `xml:"summary"`
+	Content   AtomText     `xml:"content"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Author    []AtomPerson `xml:"author"`
+
+	MediaGroup MediaGroup `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AtomText is an Atom text construct. Text and html content arrive as
+// character data, xhtml content as inline markup.
+type AtomText struct {
+	Type     string `xml:"type,attr"`
+	CharData string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.InnerXML)
+	}
+	return strings.TrimSpace(t.CharData)
+}
+
+func parseAtom(dat []byte) (*Feed, error) {
+	var atomFeed AtomFeed
+	err := xml.Unmarshal(dat, &atomFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       atomFeed.Title.String(),
+		Link:        alternateLink(atomFeed.Link),
+		Description: atomFeed.Subtitle.String(),
+		Items:       make([]Item, 0, len(atomFeed.Entry)),
+	}
+	for _, entry := range atomFeed.Entry {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		names := make([]string, 0, len(entry.Author))
+		for _, author := range entry.Author {
+			if name := strings.TrimSpace(author.Name); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		feed.Items = append(feed.Items, Item{
+			GUID:        strings.TrimSpace(entry.ID),
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Link),
+			Description: description,
+			Content:     entry.Content.String(),
+			PubDate:     strings.TrimSpace(pubDate),
+			Author:      strings.Join(names, ", "),
+			Enclosures:  collectEnclosures(enclosureLinks(entry.Link), entry.MediaGroup.Content, ""),
+			Image:       firstThumbnail(entry.MediaGroup.Thumbnail),
+		})
+	}
+
+	return feed, nil
+}
+
+func enclosureLinks(links []AtomLink) []RSSEnclosure {
+	var enclosures []RSSEnclosure
+	for _, link := range links {
+		if link.Rel == "enclosure" {
+			enclosures = append(enclosures, RSSEnclosure{
+				URL:    link.Href,
+				Type:   link.Type,
+				Length: link.Length,
+			})
+		}
+	}
+	return enclosures
+}
+
+// alternateLink picks the link pointing at the human-readable page. Atom
+// treats a link without a rel attribute as rel="alternate".
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package rss
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAtom(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want *Feed
+	}{
+		{
+			name: "entries",
+			doc: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <subtitle type="html">Notes &amp;amp; links</subtitle>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>
+    <title>First post</title>
+    <link rel="alternate" type="text/html" href="https://example.com/first"/>
+    <link rel="enclosure" type="audio/mpeg" length="1337" href="https://example.com/first.mp3"/>
+    <summary>A short summary</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Full text</p></div></content>
+    <published>2024-01-02T03:04:05Z</published>
+    <updated>2024-01-03T03:04:05Z</updated>
+    <author><name>Ada</name></author>
+    <author><name>Grace</name></author>
+  </entry>
+  <entry>
+    <id>tag:example.com,2024:second</id>
+    <title type="html">Second &lt;em&gt;post&lt;/em&gt;</title>
+    <link href="https://example.com/second"/>
+    <content type="html">&lt;p&gt;Only content&lt;/p&gt;</content>
+    <updated>2024-01-04T00:00:00Z</updated>
+  </entry>
+</feed>`,
+			want: &Feed{
+				Title:       "Example Blog",
+				Link:        "https://example.com/",
+				Description: "Notes &amp; links",
+				Items: []Item{
+					{
+						GUID:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
+						Title:       "First post",
+						Link:        "https://example.com/first",
+						Description: "A short summary",
+						Content:     `<div xmlns="http://www.w3.org/1999/xhtml"><p>Full text</p></div>`,
+						PubDate:     "2024-01-02T03:04:05Z",
+						Author:      "Ada, Grace",
+						Enclosures: []Enclosure{
+							{URL: "https://example.com/first.mp3", Type: "audio/mpeg", Length: 1337},
+						},
+					},
+					{
+						GUID:        "tag:example.com,2024:second",
+						Title:       "Second <em>post</em>",
+						Link:        "https://example.com/second",
+						Description: "<p>Only content</p>",
+						Content:     "<p>Only content</p>",
+						PubDate:     "2024-01-04T00:00:00Z",
+					},
+				},
+			},
+		},
+		{
+			name: "entry without id falls back to its link",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>No ids</title>
+  <entry>
+    <title>Linked</title>
+    <link rel="related" href="https://example.com/related"/>
+  </entry>
+</feed>`,
+			want: &Feed{
+				Title: "No ids",
+				Items: []Item{
+					{
+						GUID:  "https://example.com/related",
+						Title: "Linked",
+						Link:  "https://example.com/related",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse("application/atom+xml", []byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "empty document", doc: ""},
+		{name: "html page", doc: "<html><body>Not a feed</body></html>"},
+		{name: "atom root in the wrong namespace", doc: "<feed><entry/></feed>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse("text/xml", []byte(tt.doc)); err == nil {
+				t.Error("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestFillGUIDs(t *testing.T) {
+	feed := &Feed{Items: []Item{
+		{GUID: "kept", Link: "https://example.com/kept"},
+		{Link: "https://example.com/linked"},
+		{Title: "Untitled", PubDate: "Mon, 01 Jan 2024 00:00:00 GMT", Description: "Same"},
+		{Title: "Untitled", PubDate: "Mon, 01 Jan 2024 00:00:00 GMT", Description: "Same"},
+		{Title: "Untitled", PubDate: "Tue, 02 Jan 2024 00:00:00 GMT", Description: "Same"},
+	}}
+
+	fillGUIDs(feed)
+
+	if got := feed.Items[0].GUID; got != "kept" {
+		t.Errorf("item with a guid got %q, want it kept", got)
+	}
+	if got := feed.Items[1].GUID; got != "https://example.com/linked" {
+		t.Errorf("item with a link got %q, want the link", got)
+	}
+	hashed := feed.Items[2].GUID
+	if len(hashed) != len("sha1:")+40 || hashed[:5] != "sha1:" {
+		t.Errorf("item without guid or link got %q, want a sha1: hash", hashed)
+	}
+	if feed.Items[3].GUID != hashed {
+		t.Errorf("identical items got %q and %q, want the same hash", hashed, feed.Items[3].GUID)
+	}
+	if feed.Items[4].GUID == hashed {
+		t.Errorf("items with different dates both got %q", hashed)
+	}
+}
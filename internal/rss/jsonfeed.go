@@ -0,0 +1,143 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            JSONFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Image         string               `json:"image"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	// Author is the JSON Feed 1.0 single author, superseded by Authors in 1.1
+	Author *JSONFeedAuthor `json:"author"`
+}
+
+// JSONFeedID is an item id. The spec says it's a string, but some feeds
+// publish it as a number, which is kept as its JSON text
+type JSONFeedID string
+
+func (id *JSONFeedID) UnmarshalJSON(data []byte) error {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case string:
+		*id = JSONFeedID(value)
+	case json.Number:
+		*id = JSONFeedID(value.String())
+	case nil:
+		*id = ""
+	default:
+		return fmt.Errorf("item id must be a string or a number, got %s", data)
+	}
+	return nil
+}
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func parseJSONFeed(dat []byte) (*Feed, error) {
+	var jsonFeed JSONFeed
+	err := json.Unmarshal(dat, &jsonFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       jsonFeed.Title,
+		Link:        jsonFeed.HomePageURL,
+		Description: jsonFeed.Description,
+		Items:       make([]Item, 0, len(jsonFeed.Items)),
+	}
+	for _, item := range jsonFeed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		description := item.Summary
+		if description == "" {
+			description = content
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		authors := item.Authors
+		if len(authors) == 0 && item.Author != nil {
+			authors = []JSONFeedAuthor{*item.Author}
+		}
+		names := make([]string, 0, len(authors))
+		for _, author := range authors {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+
+		var enclosures []Enclosure
+		for _, attachment := range item.Attachments {
+			if attachment.URL == "" {
+				continue
+			}
+			enclosures = append(enclosures, Enclosure{
+				URL:      attachment.URL,
+				Type:     attachment.MimeType,
+				Length:   attachment.SizeInBytes,
+				Duration: int(attachment.DurationInSeconds),
+			})
+		}
+
+		feed.Items = append(feed.Items, Item{
+			GUID:        strings.TrimSpace(string(item.ID)),
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			Content:     content,
+			PubDate:     pubDate,
+			Author:      strings.Join(names, ", "),
+			Enclosures:  enclosures,
+			Image:       item.Image,
+		})
+	}
+
+	return feed, nil
+}
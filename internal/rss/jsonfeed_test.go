@@ -0,0 +1,134 @@
+package rss
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		doc         string
+		want        *Feed
+	}{
+		{
+			name:        "items",
+			contentType: "application/feed+json",
+			doc: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example Podcast",
+  "home_page_url": "https://example.com/",
+  "description": "Weekly episodes",
+  "items": [
+    {
+      "id": "episode-1",
+      "url": "https://example.com/1",
+      "title": "Episode 1",
+      "content_html": "<p>Show notes</p>",
+      "summary": "Short notes",
+      "date_published": "2024-01-02T03:04:05Z",
+      "image": "https://example.com/1.png",
+      "authors": [{"name": "Ada"}, {"name": ""}, {"name": "Grace"}],
+      "attachments": [
+        {"url": "https://example.com/1.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1337, "duration_in_seconds": 61.5},
+        {"mime_type": "audio/mpeg"}
+      ]
+    },
+    {
+      "id": " episode-2 ",
+      "external_url": "https://elsewhere.example.com/2",
+      "content_text": "Plain text",
+      "date_modified": "2024-01-09T00:00:00Z",
+      "author": {"name": "Linus"}
+    }
+  ]
+}`,
+			want: &Feed{
+				Title:       "Example Podcast",
+				Link:        "https://example.com/",
+				Description: "Weekly episodes",
+				Items: []Item{
+					{
+						GUID:        "episode-1",
+						Title:       "Episode 1",
+						Link:        "https://example.com/1",
+						Description: "Short notes",
+						Content:     "<p>Show notes</p>",
+						PubDate:     "2024-01-02T03:04:05Z",
+						Author:      "Ada, Grace",
+						Enclosures: []Enclosure{
+							{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 1337, Duration: 61},
+						},
+						Image: "https://example.com/1.png",
+					},
+					{
+						GUID:        "episode-2",
+						Link:        "https://elsewhere.example.com/2",
+						Description: "Plain text",
+						Content:     "Plain text",
+						PubDate:     "2024-01-09T00:00:00Z",
+						Author:      "Linus",
+					},
+				},
+			},
+		},
+		{
+			name:        "numeric ids",
+			contentType: "application/json",
+			doc: `{"version": "https://jsonfeed.org/version/1", "title": "Numbers", "items": [
+  {"id": 123, "url": "https://example.com/123"},
+  {"id": 1.5e3, "url": "https://example.com/1500"}
+]}`,
+			want: &Feed{
+				Title: "Numbers",
+				Items: []Item{
+					{GUID: "123", Link: "https://example.com/123"},
+					{GUID: "1.5e3", Link: "https://example.com/1500"},
+				},
+			},
+		},
+		{
+			name:        "missing id falls back to the url",
+			contentType: "text/plain",
+			doc:         ` {"title": "Sniffed", "items": [{"id": null, "url": "https://example.com/a"}]}`,
+			want: &Feed{
+				Title: "Sniffed",
+				Items: []Item{
+					{GUID: "https://example.com/a", Link: "https://example.com/a"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.contentType, []byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONFeedRejectsInvalidIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "object id", doc: `{"items": [{"id": {"value": 1}}]}`},
+		{name: "boolean id", doc: `{"items": [{"id": true}]}`},
+		{name: "truncated document", doc: `{"items": [{"id": "a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse("application/feed+json", []byte(tt.doc)); err == nil {
+				t.Error("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
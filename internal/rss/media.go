@@ -0,0 +1,128 @@
+package rss
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Enclosure is a media file attached to an item, such as a podcast episode
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+	// Duration is the playing time in seconds, 0 when unknown
+	Duration int
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// MediaContent is a Media RSS <media:content> element
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+// MediaThumbnail is a Media RSS <media:thumbnail> element
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+// MediaGroup bundles alternate renditions of the same media, as used by
+// YouTube channel feeds
+type MediaGroup struct {
+	Content   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnail []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// collectEnclosures merges <enclosure> and <media:content> elements into a
+// single list, dropping repeated URLs
+func collectEnclosures(enclosures []RSSEnclosure, media []MediaContent, itunesDuration string) []Enclosure {
+	var result []Enclosure
+	seen := make(map[string]bool)
+
+	for _, e := range enclosures {
+		url := strings.TrimSpace(e.URL)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		result = append(result, Enclosure{
+			URL:      url,
+			Type:     e.Type,
+			Length:   parseLength(e.Length),
+			Duration: parseDuration(itunesDuration),
+		})
+	}
+
+	for _, m := range media {
+		url := strings.TrimSpace(m.URL)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		result = append(result, Enclosure{
+			URL:      url,
+			Type:     m.Type,
+			Length:   parseLength(m.FileSize),
+			Duration: parseDuration(m.Duration),
+		})
+	}
+
+	return result
+}
+
+func firstThumbnail(groups ...[]MediaThumbnail) string {
+	for _, thumbnails := range groups {
+		for _, t := range thumbnails {
+			if t.URL != "" {
+				return t.URL
+			}
+		}
+	}
+	return ""
+}
+
+func parseLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseDuration reads an itunes:duration or media:content duration, which
+// is either a number of seconds or [[HH:]MM:]SS
+func parseDuration(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	total := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		total = total*60 + int(n)
+	}
+	return total
+}
+
+func parseEpisode(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
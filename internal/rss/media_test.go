@@ -0,0 +1,26 @@
+package rss
+
+import "testing"
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", 0},
+		{"90", 90},
+		{" 90.7 ", 90},
+		{"1:30", 90},
+		{"01:02:03", 3723},
+		{"abc", 0},
+		{"1:xx", 0},
+		{"-5", 0},
+		{"1::30", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseDuration(tt.value); got != tt.want {
+			t.Errorf("parseDuration(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
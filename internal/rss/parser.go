@@ -1,32 +1,155 @@
 package rss
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"html"
 	"io"
+	"mime"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// ErrNotModified is matched by the *NotModifiedError FetchFeed returns when
+// the server answers a conditional request with 304 Not Modified
+var ErrNotModified = errors.New("feed not modified")
+
+// NotModifiedError is returned by FetchFeed for a 304 Not Modified answer
+type NotModifiedError struct {
+	// Schedule holds the caching hints of the 304 response; a 304 has no
+	// body, so the hints from the feed document are missing
+	Schedule Schedule
+}
+
+func (e *NotModifiedError) Error() string {
+	return ErrNotModified.Error()
+}
+
+func (e *NotModifiedError) Is(target error) bool {
+	return target == ErrNotModified
+}
+
+// ParseError is returned by FetchFeed when the response body isn't a feed it
+// can read
+type ParseError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *ParseError) Error() string {
+	return "couldn't parse feed: " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned by FetchFeed when the server answers with a
+// non-2xx status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is the delay the server asked for, 0 when none was given
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected status: " + e.Status
+}
+
+// Feed is the format-independent representation of a fetched feed
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []Item
+	// Validators are the cache validators the server sent with this copy
+	Validators Validators
+	// Schedule holds the publisher's polling hints
+	Schedule Schedule
+	// StatusCode and Size describe the HTTP response the feed was read from
+	StatusCode int
+	Size       int64
+}
+
+// Validators are the HTTP cache validators of a previously fetched copy of a
+// feed, sent back as If-None-Match and If-Modified-Since
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Item is a single entry of a Feed. GUID identifies the item within its
+// feed and is never empty after Parse. Content holds the full article body
+// when the feed carries one separately from the (often truncated)
+// description. Episode and Image come from the itunes: namespace on podcast
+// feeds.
+type Item struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	Content     string
+	PubDate     string
+	Author      string
+	Enclosures  []Enclosure
+	Episode     int
+	Image       string
+}
+
+const acceptHeader = "application/rss+xml, application/atom+xml, application/rdf+xml, application/feed+json, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
 type RSSFeed struct {
 	Channel struct {
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
+		TTL         string    `xml:"ttl"`
+		SkipHours   SkipHours `xml:"skipHours"`
+		SkipDays    SkipDays  `xml:"skipDays"`
 		Item        []RSSItem `xml:"item"`
+
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	GUID        RSSGUID `xml:"guid"`
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Content     string  `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string  `xml:"pubDate"`
+	Author      string  `xml:"author"`
+	Creator     string  `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Date        string  `xml:"http://purl.org/dc/elements/1.1/ date"`
+
+	Enclosure      []RSSEnclosure   `xml:"enclosure"`
+	MediaContent   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroup     MediaGroup       `xml:"http://search.yahoo.com/mrss/ group"`
+	ITunesDuration string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode  string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesImage    ITunesImage      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
 }
 
-// FetchFeed retrieves and parses an RSS feed from the given URL
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// FetchFeed retrieves and parses an RSS 2.0, RSS 1.0, Atom or JSON feed from
+// the given URL. When validators from an earlier fetch are given the request
+// is conditional, and a *NotModifiedError is returned if the feed is
+// unchanged.
+func FetchFeed(ctx context.Context, feedURL string, validators Validators) (*Feed, error) {
 	httpClient := http.Client{
 		Timeout: 10 * time.Second,
 	}
@@ -36,30 +159,201 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	}
 
 	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("Accept", acceptHeader)
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, &NotModifiedError{Schedule: Schedule{
+			MaxAge:     maxAge(resp.Header),
+			RetryAfter: retryAfter(resp.Header, time.Now()),
+		}}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: retryAfter(resp.Header, time.Now()),
+		}
+	}
+
 	dat, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	var rssFeed RSSFeed
-	err = xml.Unmarshal(dat, &rssFeed)
+	feed, err := Parse(resp.Header.Get("Content-Type"), dat)
 	if err != nil {
-		return nil, err
+		return nil, &ParseError{StatusCode: resp.StatusCode, Err: err}
+	}
+
+	feed.Validators = Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
 	}
+	feed.Schedule.MaxAge = maxAge(resp.Header)
+	feed.Schedule.RetryAfter = retryAfter(resp.Header, time.Now())
+	feed.StatusCode = resp.StatusCode
+	feed.Size = int64(len(dat))
 
-	rssFeed.Channel.Title = html.UnescapeString(rssFeed.Channel.Title)
-	rssFeed.Channel.Description = html.UnescapeString(rssFeed.Channel.Description)
-	for i, item := range rssFeed.Channel.Item {
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+	for i, item := range feed.Items {
 		item.Title = html.UnescapeString(item.Title)
 		item.Description = html.UnescapeString(item.Description)
-		rssFeed.Channel.Item[i] = item
+		feed.Items[i] = item
 	}
 
-	return &rssFeed, nil
+	return feed, nil
+}
+
+// Parse decodes a feed document into a Feed. JSON Feed is recognised by its
+// Content-Type or by the body starting with '{'; XML formats are told apart
+// by their root element.
+func Parse(contentType string, dat []byte) (*Feed, error) {
+	var feed *Feed
+	var err error
+	if isJSONFeed(contentType, dat) {
+		feed, err = parseJSONFeed(dat)
+	} else {
+		feed, err = parseXML(dat)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fillGUIDs(feed)
+	return feed, nil
+}
+
+func parseXML(dat []byte) (*Feed, error) {
+	root, err := rootElement(dat)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.Local == "rss":
+		return parseRSS(dat)
+	case root.Local == "feed" && root.Space == atomNamespace:
+		return parseAtom(dat)
+	case root.Local == "RDF":
+		return parseRDF(dat)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root.Local)
+	}
+}
+
+func isJSONFeed(contentType string, dat []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/feed+json", "application/json":
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(dat), []byte("{"))
+}
+
+func rootElement(dat []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(dat))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return xml.Name{}, fmt.Errorf("empty feed document")
+			}
+			return xml.Name{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func parseRSS(dat []byte) (*Feed, error) {
+	var rssFeed RSSFeed
+	err := xml.Unmarshal(dat, &rssFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       rssFeed.Channel.Title,
+		Link:        rssFeed.Channel.Link,
+		Description: rssFeed.Channel.Description,
+		Items:       make([]Item, 0, len(rssFeed.Channel.Item)),
+		Schedule: channelSchedule(
+			rssFeed.Channel.TTL,
+			rssFeed.Channel.SkipHours,
+			rssFeed.Channel.SkipDays,
+			rssFeed.Channel.UpdatePeriod,
+			rssFeed.Channel.UpdateFrequency,
+		),
+	}
+	for _, item := range rssFeed.Channel.Item {
+		guid := strings.TrimSpace(item.GUID.Value)
+		link := strings.TrimSpace(item.Link)
+		// A guid is a permalink unless it says otherwise, so it can stand in
+		// for a missing <link>
+		if link == "" && item.GUID.IsPermaLink != "false" && isHTTPURL(guid) {
+			link = guid
+		}
+
+		feed.Items = append(feed.Items, Item{
+			GUID:        guid,
+			Title:       item.Title,
+			Link:        link,
+			Description: item.Description,
+			Content:     strings.TrimSpace(item.Content),
+			PubDate:     firstNonEmpty(item.PubDate, item.Date),
+			Author:      firstNonEmpty(item.Creator, item.Author),
+			Enclosures: collectEnclosures(
+				item.Enclosure,
+				append(item.MediaContent, item.MediaGroup.Content...),
+				item.ITunesDuration,
+			),
+			Episode: parseEpisode(item.ITunesEpisode),
+			Image:   firstNonEmpty(item.ITunesImage.Href, firstThumbnail(item.MediaThumbnail, item.MediaGroup.Thumbnail)),
+		})
+	}
+
+	return feed, nil
+}
+
+// fillGUIDs gives every item an identifier, falling back to its link and
+// then to a hash of its contents for feeds that publish neither
+func fillGUIDs(feed *Feed) {
+	for i, item := range feed.Items {
+		if item.GUID != "" {
+			continue
+		}
+		if item.Link != "" {
+			feed.Items[i].GUID = item.Link
+			continue
+		}
+		sum := sha1.Sum([]byte(item.Title + "\x00" + item.PubDate + "\x00" + item.Description))
+		feed.Items[i].GUID = "sha1:" + hex.EncodeToString(sum[:])
+	}
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
 }
@@ -0,0 +1,61 @@
+package rss
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// RDFFeed is an RSS 1.0 document. Unlike RSS 2.0 its items are siblings of
+// the channel rather than children of it.
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+func parseRDF(dat []byte) (*Feed, error) {
+	var rdfFeed RDFFeed
+	err := xml.Unmarshal(dat, &rdfFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       rdfFeed.Channel.Title,
+		Link:        rdfFeed.Channel.Link,
+		Description: rdfFeed.Channel.Description,
+		Items:       make([]Item, 0, len(rdfFeed.Item)),
+		Schedule: Schedule{
+			UpdatePeriod: syndicationPeriod(rdfFeed.Channel.UpdatePeriod, rdfFeed.Channel.UpdateFrequency),
+		},
+	}
+	for _, item := range rdfFeed.Item {
+		feed.Items = append(feed.Items, Item{
+			GUID:        strings.TrimSpace(item.About),
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     strings.TrimSpace(item.Content),
+			PubDate:     item.Date,
+			Author:      firstNonEmpty(item.Creator),
+		})
+	}
+
+	return feed, nil
+}
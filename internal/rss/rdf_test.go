@@ -0,0 +1,90 @@
+package rss
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRDF(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want *Feed
+	}{
+		{
+			name: "items beside the channel",
+			doc: `<?xml version="1.0"?>
+<rdf:RDF
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns:content="http://purl.org/rss/1.0/modules/content/"
+  xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel rdf:about="https://example.com/">
+    <title>Example RDF</title>
+    <link>https://example.com/</link>
+    <description>An RSS 1.0 channel</description>
+    <sy:updatePeriod>daily</sy:updatePeriod>
+    <sy:updateFrequency>4</sy:updateFrequency>
+  </channel>
+  <item rdf:about="https://example.com/one">
+    <title>One</title>
+    <link>https://example.com/one</link>
+    <description>First item</description>
+    <content:encoded><![CDATA[ <p>Full text</p> ]]></content:encoded>
+    <dc:date>2024-01-02T03:04:05Z</dc:date>
+    <dc:creator> Ada </dc:creator>
+  </item>
+  <item>
+    <title>Two</title>
+    <link>https://example.com/two</link>
+  </item>
+</rdf:RDF>`,
+			want: &Feed{
+				Title:       "Example RDF",
+				Link:        "https://example.com/",
+				Description: "An RSS 1.0 channel",
+				Schedule:    Schedule{UpdatePeriod: 6 * time.Hour},
+				Items: []Item{
+					{
+						GUID:        "https://example.com/one",
+						Title:       "One",
+						Link:        "https://example.com/one",
+						Description: "First item",
+						Content:     "<p>Full text</p>",
+						PubDate:     "2024-01-02T03:04:05Z",
+						Author:      "Ada",
+					},
+					{
+						GUID:  "https://example.com/two",
+						Title: "Two",
+						Link:  "https://example.com/two",
+					},
+				},
+			},
+		},
+		{
+			name: "no syndication hints",
+			doc: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel><title>Quiet</title></channel>
+</rdf:RDF>`,
+			want: &Feed{
+				Title: "Quiet",
+				Items: []Item{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse("application/rdf+xml", []byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package rss
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule holds the hints a publisher gives about how often a feed should
+// be polled, from the feed document and from the HTTP response
+type Schedule struct {
+	// TTL is the RSS <ttl>, how long the feed may be cached
+	TTL time.Duration
+	// UpdatePeriod is sy:updatePeriod divided by sy:updateFrequency
+	UpdatePeriod time.Duration
+	// SkipHours are the UTC hours in which the feed should not be polled
+	SkipHours []int
+	// SkipDays are the weekdays on which the feed should not be polled
+	SkipDays []time.Weekday
+	// MaxAge is the Cache-Control max-age of the response
+	MaxAge time.Duration
+	// RetryAfter is the Retry-After delay of the response
+	RetryAfter time.Duration
+}
+
+// SkipHours and SkipDays are the RSS 2.0 <skipHours> and <skipDays> elements.
+// Hours are decoded as text so that a malformed hint can be dropped instead
+// of failing the whole document.
+type SkipHours struct {
+	Hour []string `xml:"hour"`
+}
+
+type SkipDays struct {
+	Day []string `xml:"day"`
+}
+
+func channelSchedule(ttl string, skipHours SkipHours, skipDays SkipDays, updatePeriod, updateFrequency string) Schedule {
+	var schedule Schedule
+
+	if minutes, err := strconv.Atoi(strings.TrimSpace(ttl)); err == nil && minutes > 0 {
+		schedule.TTL = time.Duration(minutes) * time.Minute
+	}
+
+	schedule.UpdatePeriod = syndicationPeriod(updatePeriod, updateFrequency)
+
+	for _, value := range skipHours.Hour {
+		hour, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil && hour >= 0 && hour <= 23 {
+			schedule.SkipHours = append(schedule.SkipHours, hour)
+		}
+	}
+
+	for _, day := range skipDays.Day {
+		if weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]; ok {
+			schedule.SkipDays = append(schedule.SkipDays, weekday)
+		}
+	}
+
+	return schedule
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// syndicationPeriod converts the RSS 1.0 syndication module's period and
+// frequency (updates per period, default 1) into an interval
+func syndicationPeriod(period, frequency string) time.Duration {
+	var length time.Duration
+	switch strings.ToLower(strings.TrimSpace(period)) {
+	case "hourly":
+		length = time.Hour
+	case "daily":
+		length = 24 * time.Hour
+	case "weekly":
+		length = 7 * 24 * time.Hour
+	case "monthly":
+		length = 30 * 24 * time.Hour
+	case "yearly":
+		length = 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(frequency))
+	if err != nil || n < 1 {
+		n = 1
+	}
+	return length / time.Duration(n)
+}
+
+// maxAge reads the max-age directive of a Cache-Control header
+func maxAge(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// retryAfter reads a Retry-After header given either as seconds or as an
+// HTTP date
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
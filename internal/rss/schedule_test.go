@@ -0,0 +1,128 @@
+package rss
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRSSSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		want    Schedule
+	}{
+		{
+			name: "all hints",
+			channel: `<ttl>90</ttl>
+<sy:updatePeriod>hourly</sy:updatePeriod>
+<sy:updateFrequency>2</sy:updateFrequency>
+<skipHours><hour>0</hour><hour> 23 </hour></skipHours>
+<skipDays><day>Saturday</day><day>sunday</day></skipDays>`,
+			want: Schedule{
+				TTL:          90 * time.Minute,
+				UpdatePeriod: 30 * time.Minute,
+				SkipHours:    []int{0, 23},
+				SkipDays:     []time.Weekday{time.Saturday, time.Sunday},
+			},
+		},
+		{
+			name: "malformed hints are dropped",
+			channel: `<ttl>soon</ttl>
+<sy:updatePeriod>fortnightly</sy:updatePeriod>
+<skipHours><hour>noon</hour><hour>24</hour><hour>-1</hour><hour>7</hour><hour></hour></skipHours>
+<skipDays><day>Funday</day><day>Monday</day></skipDays>`,
+			want: Schedule{
+				SkipHours: []int{7},
+				SkipDays:  []time.Weekday{time.Monday},
+			},
+		},
+		{
+			name:    "non-positive ttl and frequency",
+			channel: `<ttl>0</ttl><sy:updatePeriod>daily</sy:updatePeriod><sy:updateFrequency>0</sy:updateFrequency>`,
+			want:    Schedule{UpdatePeriod: 24 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/"><channel><title>Hints</title>` +
+				tt.channel + `</channel></rss>`
+			feed, err := Parse("application/rss+xml", []byte(doc))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(feed.Schedule, tt.want) {
+				t.Errorf("Schedule = %+v, want %+v", feed.Schedule, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyndicationPeriod(t *testing.T) {
+	tests := []struct {
+		period    string
+		frequency string
+		want      time.Duration
+	}{
+		{"hourly", "", time.Hour},
+		{"Daily", "1", 24 * time.Hour},
+		{"weekly", "7", 24 * time.Hour},
+		{"monthly", "-3", 30 * 24 * time.Hour},
+		{"yearly", "x", 365 * 24 * time.Hour},
+		{"", "4", 0},
+		{"never", "1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := syndicationPeriod(tt.period, tt.frequency); got != tt.want {
+			t.Errorf("syndicationPeriod(%q, %q) = %v, want %v", tt.period, tt.frequency, got, tt.want)
+		}
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+	}{
+		{"", 0},
+		{"max-age=300", 5 * time.Minute},
+		{"public, Max-Age=\"60\", must-revalidate", time.Minute},
+		{"no-cache", 0},
+		{"max-age=-5", 0},
+		{"max-age=abc", 0},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("Cache-Control", tt.cacheControl)
+		if got := maxAge(header); got != tt.want {
+			t.Errorf("maxAge(%q) = %v, want %v", tt.cacheControl, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-1", 0},
+		{now.Add(time.Hour).Format(http.TimeFormat), time.Hour},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0},
+		{"tomorrow", 0},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("Retry-After", tt.value)
+		if got := retryAfter(header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mrjacz/gator/internal/database"
+)
+
+// Fields a rule can match against. FieldAny matches the title, the
+// description or the content.
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldContent     = "content"
+	FieldURL         = "url"
+	FieldAny         = "any"
+)
+
+var fields = []string{FieldTitle, FieldDescription, FieldContent, FieldURL, FieldAny}
+
+// Post holds the parts of a post that rules are matched against
+type Post struct {
+	Title       string
+	Description string
+	Content     string
+	URL         string
+}
+
+// Matcher is a filter rule compiled for matching
+type Matcher struct {
+	Rule   database.FilterRule
+	re     *regexp.Regexp
+	needle string
+}
+
+// Compile prepares a rule for matching. Matching is case-insensitive, both
+// for substrings and for regular expressions.
+func Compile(rule database.FilterRule) (*Matcher, error) {
+	if err := Validate(rule.Field, rule.Pattern, rule.IsRegex); err != nil {
+		return nil, err
+	}
+
+	m := &Matcher{Rule: rule}
+	if rule.IsRegex {
+		m.re = regexp.MustCompile("(?i)" + rule.Pattern)
+	} else {
+		m.needle = strings.ToLower(rule.Pattern)
+	}
+	return m, nil
+}
+
+// CompileKeyword prepares a pattern that isn't part of a filter rule, such as
+// a webhook's keyword, to be matched like a rule on FieldAny
+func CompileKeyword(pattern string, isRegex bool) (*Matcher, error) {
+	return Compile(database.FilterRule{Field: FieldAny, Pattern: pattern, IsRegex: isRegex})
+}
+
+// Match reports whether the rule's pattern is found in the post
+func (m *Matcher) Match(post Post) bool {
+	var values []string
+	switch m.Rule.Field {
+	case FieldTitle:
+		values = []string{post.Title}
+	case FieldDescription:
+		values = []string{post.Description}
+	case FieldContent:
+		values = []string{post.Content}
+	case FieldURL:
+		values = []string{post.URL}
+	default:
+		values = []string{post.Title, post.Description, post.Content}
+	}
+
+	for _, value := range values {
+		if m.re != nil && m.re.MatchString(value) {
+			return true
+		}
+		if m.re == nil && strings.Contains(strings.ToLower(value), m.needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the field and pattern of a rule
+func Validate(field, pattern string, isRegex bool) error {
+	if !slices.Contains(fields, field) {
+		return fmt.Errorf("invalid field %q (must be %s)", field, strings.Join(fields, ", "))
+	}
+	if pattern == "" {
+		return errors.New("pattern must not be empty")
+	}
+	if isRegex {
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return nil
+}
+
+// ParsePattern reads a pattern as typed on the command line: /.../ is a
+// regular expression, anything else a substring
+func ParsePattern(s string) (pattern string, isRegex bool) {
+	if len(s) >= 2 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/") {
+		return s[1 : len(s)-1], true
+	}
+	return s, false
+}
+
+// Describe renders a rule as "title matches /x/ → hide, tag golang"
+func Describe(rule database.FilterRule) string {
+	var condition string
+	if rule.IsRegex {
+		condition = fmt.Sprintf("%s matches /%s/", rule.Field, rule.Pattern)
+	} else {
+		condition = fmt.Sprintf("%s contains %q", rule.Field, rule.Pattern)
+	}
+
+	var actions []string
+	if rule.Hide {
+		actions = append(actions, "hide")
+	}
+	if rule.Bookmark {
+		actions = append(actions, "bookmark")
+	}
+	if rule.Tag.Valid {
+		actions = append(actions, "tag "+rule.Tag.String)
+	}
+	return condition + " → " + strings.Join(actions, ", ")
+}
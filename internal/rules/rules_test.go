@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mrjacz/gator/internal/database"
+)
+
+func TestMatch(t *testing.T) {
+	post := Post{
+		Title:       "Go 1.22 Released",
+		Description: "Range over integers and a new router",
+		Content:     "<p>Sponsored: try our hosting</p>",
+		URL:         "https://go.dev/blog/go1.22",
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		pattern string
+		isRegex bool
+		want    bool
+	}{
+		{"title substring ignores case", FieldTitle, "go 1.22", false, true},
+		{"title substring missing", FieldTitle, "rust", false, false},
+		{"description only", FieldDescription, "router", false, true},
+		{"description doesn't look at the title", FieldDescription, "released", false, false},
+		{"content", FieldContent, "sponsored", false, true},
+		{"url", FieldURL, "go.dev/blog", false, true},
+		{"url isn't part of any", FieldAny, "go.dev", false, false},
+		{"any looks at the title", FieldAny, "RELEASED", false, true},
+		{"any looks at the content", FieldAny, "hosting", false, true},
+		{"regex ignores case", FieldTitle, `^go \d+\.\d+`, true, true},
+		{"regex anchored at the end", FieldTitle, `released$`, true, true},
+		{"regex no match", FieldTitle, `^rust`, true, false},
+		{"regex special characters are literal in substrings", FieldURL, `go1.22$`, false, false},
+		{"regex on the url", FieldURL, `go1\.22$`, true, true},
+		{"regex on any", FieldAny, `integers|generics`, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(database.FilterRule{Field: tt.field, Pattern: tt.pattern, IsRegex: tt.isRegex})
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := m.Match(post); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		pattern string
+		isRegex bool
+		wantErr bool
+	}{
+		{"substring", FieldTitle, "go", false, false},
+		{"regex", FieldAny, `\bgo\b`, true, false},
+		{"unknown field", "author", "go", false, true},
+		{"empty pattern", FieldTitle, "", false, true},
+		{"invalid regex", FieldTitle, "(go", true, true},
+		{"invalid regex as a substring", FieldTitle, "(go", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.field, tt.pattern, tt.isRegex)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantPattern string
+		wantRegex   bool
+	}{
+		{"golang", "golang", false},
+		{"/go(lang)?/", "go(lang)?", true},
+		{"//", "", true},
+		{"/", "/", false},
+		{"/half", "/half", false},
+	}
+
+	for _, tt := range tests {
+		pattern, isRegex := ParsePattern(tt.input)
+		if pattern != tt.wantPattern || isRegex != tt.wantRegex {
+			t.Errorf("ParsePattern(%q) = %q, %v, want %q, %v", tt.input, pattern, isRegex, tt.wantPattern, tt.wantRegex)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		rule database.FilterRule
+		want string
+	}{
+		{
+			rule: database.FilterRule{Field: FieldTitle, Pattern: "ad", Hide: true},
+			want: `title contains "ad" → hide`,
+		},
+		{
+			rule: database.FilterRule{
+				Field:    FieldAny,
+				Pattern:  "go|rust",
+				IsRegex:  true,
+				Bookmark: true,
+				Tag:      sql.NullString{String: "langs", Valid: true},
+			},
+			want: "any matches /go|rust/ → bookmark, tag langs",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.rule); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -2,24 +2,35 @@ package main
 
 import (
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
 	_ "github.com/lib/pq"
 	"github.com/mrjacz/gator/handlers"
 	"github.com/mrjacz/gator/internal/config"
 	"github.com/mrjacz/gator/internal/database"
+	"github.com/mrjacz/gator/internal/logging"
 )
 
 func main() {
+	args, logFormat, logLevel := parseLogFlags(os.Args[1:])
+	logger, err := logging.New(os.Stderr, logFormat, logLevel)
+	if err != nil {
+		fatal(err)
+	}
+	slog.SetDefault(logger)
+
 	cfg, err := config.Read()
 	if err != nil {
-		log.Fatalf("error reading config: %v", err)
+		fatal(fmt.Errorf("error reading config: %w", err))
 	}
 
 	db, err := sql.Open("postgres", cfg.DBURL)
 	if err != nil {
-		log.Fatalf("error connecting to db: %v", err)
+		fatal(fmt.Errorf("error connecting to db: %w", err))
 	}
 	defer db.Close()
 	dbQueries := database.New(db)
@@ -37,29 +48,63 @@ func main() {
 	cmds.register("reset", handlers.Reset)
 	cmds.register("users", handlers.ListUsers)
 	cmds.register("agg", handlers.Agg)
+	cmds.register("fetch", handlers.Fetch)
 	cmds.register("server", handlers.Server)
 	cmds.register("service", handlers.Service)
 	cmds.register("addfeed", middlewareLoggedIn(handlers.AddFeed))
 	cmds.register("feeds", handlers.ListFeeds)
+	cmds.register("feed", middlewareLoggedIn(handlers.Feed))
 	cmds.register("follow", middlewareLoggedIn(handlers.Follow))
 	cmds.register("following", middlewareLoggedIn(handlers.ListFeedFollows))
 	cmds.register("unfollow", middlewareLoggedIn(handlers.Unfollow))
+	cmds.register("folder", middlewareLoggedIn(handlers.Folder))
 	cmds.register("browse", middlewareLoggedIn(handlers.Browse))
 	cmds.register("search", middlewareLoggedIn(handlers.Search))
 	cmds.register("bookmark", middlewareLoggedIn(handlers.Bookmark))
 	cmds.register("unbookmark", middlewareLoggedIn(handlers.Unbookmark))
 	cmds.register("bookmarks", middlewareLoggedIn(handlers.ListBookmarks))
+	cmds.register("read", middlewareLoggedIn(handlers.Read))
+	cmds.register("unread", middlewareLoggedIn(handlers.Unread))
+	cmds.register("mark-all-read", middlewareLoggedIn(handlers.MarkAllRead))
+	cmds.register("enclosures", middlewareLoggedIn(handlers.Enclosures))
+	cmds.register("post", middlewareLoggedIn(handlers.Post))
+	cmds.register("rules", middlewareLoggedIn(handlers.Rules))
+	cmds.register("webhooks", middlewareLoggedIn(handlers.Webhooks))
+	cmds.register("digest", middlewareLoggedIn(handlers.Digest))
 	cmds.register("tui", middlewareLoggedIn(handlers.TUI))
 
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: cli <command> [args...]")
+	if len(args) < 1 {
+		fatal(errors.New("Usage: cli [--log-format=text|json] [--log-level=LEVEL] <command> [args...]"))
 	}
 
-	cmdName := os.Args[1]
-	cmdArgs := os.Args[2:]
+	cmdName := args[0]
+	cmdArgs := args[1:]
 
 	err = cmds.run(programState, handlers.Command{Name: cmdName, Args: cmdArgs})
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+}
+
+// parseLogFlags removes the global --log-format and --log-level options,
+// which may appear anywhere on the command line, from args
+func parseLogFlags(args []string) (rest []string, format, level string) {
+	format = "text"
+	level = "info"
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--log-format=") {
+			format = strings.TrimPrefix(arg, "--log-format=")
+		} else if strings.HasPrefix(arg, "--log-level=") {
+			level = strings.TrimPrefix(arg, "--log-level=")
+		} else {
+			rest = append(rest, arg)
+		}
 	}
+	return rest, format, level
+}
+
+// fatal prints err for the user and exits; slog is for agg and server logs
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
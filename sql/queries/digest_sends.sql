@@ -0,0 +1,7 @@
+-- name: ClaimDigestSend :execrows
+-- Records a digest as sent unless one was already sent at or after
+-- not_before; 0 rows means another aggregator got there first
+INSERT INTO digest_sends (user_id, sent_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(sent_at))
+ON CONFLICT (user_id) DO UPDATE SET sent_at = EXCLUDED.sent_at
+WHERE digest_sends.sent_at < sqlc.arg(not_before);
@@ -0,0 +1,10 @@
+-- name: CreateFeedFetch :one
+INSERT INTO feed_fetches (id, feed_id, started_at, finished_at, http_status, bytes, item_count, new_post_count, updated_post_count, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetFeedFetches :many
+SELECT * FROM feed_fetches
+WHERE feed_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
-    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-    VALUES ($1, $2, $3, $4, $5)
+    INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder_id)
+    VALUES ($1, $2, $3, $4, $5, $6)
     RETURNING *
 )
 SELECT
@@ -14,13 +14,42 @@ INNER JOIN users ON inserted_feed_follow.user_id = users.id;
 --
 
 -- name: GetFeedFollowsForUser :many
-SELECT feed_follows.*, feeds.name AS feed_name, users.name AS user_name
+-- feed_name is the follower's title for the feed when they set one
+SELECT feed_follows.*, COALESCE(feed_follows.title, feeds.name)::text AS feed_name, feeds.url AS feed_url, users.name AS user_name, folders.name AS folder_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+LEFT JOIN folders ON feed_follows.folder_id = folders.id
+WHERE feed_follows.user_id = $1
+ORDER BY folders.name NULLS FIRST, feed_name;
+--
+
+-- name: GetFeedFollowForUser :one
+SELECT * FROM feed_follows
+WHERE id = $1 AND user_id = $2;
+--
+
+-- name: GetFeedFollowForFeed :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
 --
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows WHERE feed_id = $1 AND user_id = $2;
 --
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows SET folder_id = $3, updated_at = $4
+WHERE user_id = $1 AND feed_id = $2;
+--
+
+-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET title = $3, priority = $4, muted = $5, folder_id = $6, updated_at = $7
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+--
+
+-- name: CountFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows WHERE feed_id = $1;
+--
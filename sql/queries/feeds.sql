@@ -6,6 +6,15 @@ RETURNING *;
 -- name: GetFeeds :many
 SELECT * FROM feeds;
 
+-- name: GetFeedByID :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: GetFeedsByName :many
+SELECT * FROM feeds
+WHERE name = $1
+ORDER BY created_at ASC;
+
 -- name: GetFeedByURL :one
 SELECT * FROM feeds
 WHERE url = $1;
@@ -17,13 +26,131 @@ updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
--- name: GetNextFeedToFetch :one
+-- name: GetBrokenFeeds :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+WHERE disabled OR consecutive_failures > 0
+ORDER BY disabled DESC, consecutive_failures DESC;
 
--- name: GetNextFeedsToFetch :many
-SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1;
+-- name: GetFeedStats :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE disabled) AS disabled,
+    COUNT(*) FILTER (WHERE NOT disabled AND consecutive_failures > 0) AS failing,
+    COUNT(*) FILTER (WHERE NOT disabled AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())) AS due
+FROM feeds;
+
+-- name: ClaimFeed :one
+-- Leases a single feed whatever its schedule, unless another process holds it
+UPDATE feeds
+SET lease_expires_at = NOW() + (sqlc.arg(lease_seconds)::integer * INTERVAL '1 second')
+WHERE id = sqlc.arg(id)
+AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+RETURNING *;
+
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET lease_expires_at = NOW() + (sqlc.arg(lease_seconds)::integer * INTERVAL '1 second')
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT disabled
+    AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+    ORDER BY (
+        SELECT COALESCE(MAX(feed_follows.priority), 0) FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id
+    ) DESC, next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT sqlc.arg(max_feeds)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
 
+-- name: ReleaseFeedLease :exec
+-- Only the holder's own lease is cleared; once it has expired another
+-- aggregator may have claimed the feed with a new one
+UPDATE feeds
+SET lease_expires_at = NULL
+WHERE id = $1 AND lease_expires_at = $2;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET last_error = NULL,
+consecutive_failures = 0,
+last_success_at = NOW()
+WHERE id = $1;
+
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET last_error = sqlc.arg(last_error),
+consecutive_failures = consecutive_failures + 1,
+disabled = disabled OR (sqlc.arg(max_failures)::integer > 0 AND consecutive_failures + 1 >= sqlc.arg(max_failures)::integer)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: EnableFeed :one
+UPDATE feeds
+SET disabled = FALSE,
+consecutive_failures = 0,
+next_fetch_at = NULL,
+updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = NOW() + (sqlc.arg(delay_seconds)::integer * INTERVAL '1 second')
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateFeedValidators :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = $3,
+updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: SetFeedURL :one
+-- The cached validators, failure count and auto-disable belonged to the old URL
+UPDATE feeds
+SET url = $3,
+etag = NULL,
+last_modified = NULL,
+next_fetch_at = NULL,
+last_error = NULL,
+consecutive_failures = 0,
+disabled = FALSE,
+updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: UpdateFeed :one
+-- Renames a feed and changes its URL in one statement, leaving out either
+-- when it's NULL. A new URL resets the same state as SetFeedURL
+UPDATE feeds
+SET name = COALESCE(sqlc.narg(name), name),
+url = COALESCE(sqlc.narg(url), url),
+etag = CASE WHEN sqlc.narg(url) IS NULL THEN etag END,
+last_modified = CASE WHEN sqlc.narg(url) IS NULL THEN last_modified END,
+next_fetch_at = CASE WHEN sqlc.narg(url) IS NULL THEN next_fetch_at END,
+last_error = CASE WHEN sqlc.narg(url) IS NULL THEN last_error END,
+consecutive_failures = CASE WHEN sqlc.narg(url) IS NULL THEN consecutive_failures ELSE 0 END,
+disabled = disabled AND sqlc.narg(url) IS NULL,
+updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteUnfollowedFeeds :many
+-- Feeds created before the cutoff that nobody follows; newer ones may be
+-- about to get their first follow
+DELETE FROM feeds
+WHERE created_at < $1
+AND NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+RETURNING *;
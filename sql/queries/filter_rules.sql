@@ -0,0 +1,44 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, user_id, feed_id, field, pattern, is_regex, hide, bookmark, tag)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT * FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetFilterRuleForUser :one
+SELECT * FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFilterRulesForFeed :many
+-- Rules of every user following the feed that apply to all feeds or to this
+-- one in particular
+SELECT filter_rules.* FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+WHERE feed_follows.feed_id = $1
+  AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = $1)
+ORDER BY filter_rules.created_at ASC;
+
+-- name: UpdateFilterRule :one
+UPDATE filter_rules
+SET feed_id = $3,
+field = $4,
+pattern = $5,
+is_regex = $6,
+hide = $7,
+bookmark = $8,
+tag = $9,
+updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: HidePost :exec
+INSERT INTO hidden_posts (rule_id, post_id, user_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
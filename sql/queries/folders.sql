@@ -0,0 +1,29 @@
+-- name: CreateFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetFoldersForUser :many
+SELECT folders.*, COUNT(feed_follows.id) AS feed_count FROM folders
+LEFT JOIN feed_follows ON feed_follows.folder_id = folders.id
+WHERE folders.user_id = $1
+GROUP BY folders.id
+ORDER BY folders.name ASC;
+
+-- name: GetFolderForUser :one
+SELECT * FROM folders
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFolderByName :one
+SELECT * FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: RenameFolder :one
+UPDATE folders
+SET name = $3, updated_at = $4
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteFolder :execrows
+DELETE FROM folders
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,22 @@
+-- name: GetEnclosuresForPost :many
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetEnclosuresForPosts :many
+SELECT * FROM post_enclosures
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[])
+ORDER BY created_at ASC;
+
+-- name: UpsertPostEnclosure :one
+-- An updated post may bring new enclosures or new details for known ones
+INSERT INTO post_enclosures (id, created_at, updated_at, post_id, url, mime_type, length, duration_seconds, episode, image_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (post_id, url) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+mime_type = EXCLUDED.mime_type,
+length = EXCLUDED.length,
+duration_seconds = EXCLUDED.duration_seconds,
+episode = EXCLUDED.episode,
+image_url = EXCLUDED.image_url
+RETURNING *;
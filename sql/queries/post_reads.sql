@@ -0,0 +1,24 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+-- Marks the user's posts read, optionally only those of one feed or those
+-- published before a given time
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW() FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+  AND (sqlc.narg(before)::timestamp IS NULL OR posts.published_at < sqlc.narg(before))
+ON CONFLICT DO NOTHING;
+
+-- name: GetReadPostIDs :many
+-- Which of the given posts the user has read
+SELECT post_id FROM post_reads
+WHERE user_id = sqlc.arg(user_id) AND post_id = ANY(sqlc.arg(post_ids)::uuid[]);
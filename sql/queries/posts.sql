@@ -1,55 +1,186 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7,
-    $8
+-- name: UpsertPost :one
+-- Inserts a new post, or updates the stored one when the publisher changed
+-- it. The version being replaced is kept in post_revisions. Returns no rows
+-- when the post is already stored unchanged.
+WITH previous AS (
+    SELECT * FROM posts
+    WHERE posts.feed_id = sqlc.arg(feed_id) AND posts.guid = sqlc.arg(guid)
+), upserted AS (
+    INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid)
+    VALUES (
+        sqlc.arg(id),
+        sqlc.arg(created_at),
+        sqlc.arg(updated_at),
+        sqlc.arg(title),
+        sqlc.arg(url),
+        sqlc.arg(description),
+        sqlc.arg(published_at),
+        sqlc.arg(feed_id),
+        sqlc.arg(content),
+        sqlc.arg(guid)
+    )
+    ON CONFLICT (feed_id, guid) DO UPDATE
+    SET title = EXCLUDED.title,
+        url = EXCLUDED.url,
+        description = EXCLUDED.description,
+        content = EXCLUDED.content,
+        published_at = EXCLUDED.published_at,
+        updated_at = EXCLUDED.updated_at
+    WHERE (posts.title, posts.url, posts.description, posts.content)
+        IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, EXCLUDED.content)
+    RETURNING *
+), revision AS (
+    INSERT INTO post_revisions (id, created_at, post_id, title, url, description, content)
+    SELECT sqlc.arg(revision_id), sqlc.arg(updated_at), previous.id, previous.title, previous.url, previous.description, previous.content
+    FROM previous
+    JOIN upserted ON upserted.id = previous.id
 )
-RETURNING *;
+SELECT upserted.*, (previous.id IS NULL)::boolean AS inserted
+FROM upserted
+LEFT JOIN previous ON previous.id = upserted.id;
+
+-- name: GetPostForUser :one
+SELECT posts.* FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.id = $2;
 
 -- name: GetPostsForUser :many
 SELECT posts.* FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.published_at DESC
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: GetPostsForUserByFeed :many
 SELECT posts.* FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1 AND feeds.url = $2
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND feeds.url = sqlc.arg(url)
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.published_at DESC
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserByFolder :many
+SELECT posts.* FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN folders ON folders.id = feed_follows.folder_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND folders.name = sqlc.arg(folder_name)
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserByTag :many
+SELECT posts.* FROM posts
+JOIN post_tags ON post_tags.post_id = posts.id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = post_tags.user_id
+WHERE post_tags.user_id = sqlc.arg(user_id) AND post_tags.tag = sqlc.arg(tag)
+  AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.post_id = posts.id
+  ))
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: GetPostsForUserSortedByTitle :many
 SELECT posts.* FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id) AND NOT feed_follows.muted
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+  )
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.post_id = posts.id
+  ))
 ORDER BY posts.title ASC
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetRecentPostDates :many
+SELECT published_at FROM posts
+WHERE feed_id = $1
+ORDER BY published_at DESC
+LIMIT $2;
 
 -- name: SearchPostsForUser :many
 SELECT posts.* FROM posts
-JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
   AND (
     posts.title ILIKE $2
     OR posts.description ILIKE $2
   )
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
 ORDER BY posts.published_at DESC
 LIMIT $3;
 
--- name: GetPostByURL :one
+-- name: GetPostsForUserByURL :many
+-- Several feeds may link to the same page, so a URL can match more than one
+-- post
 SELECT posts.* FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1 AND posts.url = $2
+ORDER BY posts.published_at DESC;
+
+-- name: GetDigestPostsForUser :many
+-- Posts stored since the given time, grouped by feed for a digest
+SELECT posts.*, COALESCE(feed_follows.title, feeds.name)::text AS feed_name, feeds.url AS feed_url FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
-WHERE feeds.user_id = $1 AND posts.url = $2
-LIMIT 1;
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1 AND NOT feed_follows.muted
+  AND posts.created_at >= $2
+  AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+  )
+ORDER BY feed_name ASC, feeds.id, posts.published_at DESC
+LIMIT $3;
 
+-- name: AdoptPostGUID :execrows
+-- Posts stored before items had GUIDs were given their URL as GUID. When the
+-- feed supplies a real GUID for the item, move the stored post over to it so
+-- the item isn't inserted a second time.
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE posts.feed_id = sqlc.arg(feed_id) AND posts.url = sqlc.arg(url) AND posts.guid = posts.url
+  AND NOT EXISTS (
+    SELECT 1 FROM posts AS existing
+    WHERE existing.feed_id = sqlc.arg(feed_id) AND existing.guid = sqlc.arg(guid)
+  );
@@ -0,0 +1,37 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, secret, feed_id, keyword, is_regex)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT * FROM webhooks
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetWebhookForUser :one
+SELECT * FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetWebhooksForFeed :many
+-- Webhooks of every user following the feed, and not muting it, that apply
+-- to all feeds or to this one in particular
+SELECT webhooks.* FROM webhooks
+JOIN feed_follows ON feed_follows.user_id = webhooks.user_id
+WHERE feed_follows.feed_id = $1 AND NOT feed_follows.muted
+  AND (webhooks.feed_id IS NULL OR webhooks.feed_id = $1)
+ORDER BY webhooks.created_at ASC;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, webhook_id, post_id, event, attempt, started_at, duration_ms, status_code, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
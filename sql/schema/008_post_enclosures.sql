@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE post_enclosures (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    length BIGINT NOT NULL,
+    duration_seconds INTEGER,
+    episode INTEGER,
+    image_url TEXT,
+    UNIQUE(post_id, url)
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT NOT NULL,
+    content TEXT NOT NULL
+);
+
+CREATE INDEX post_revisions_post_id_idx ON post_revisions (post_id, created_at);
+
+-- +goose Down
+DROP TABLE post_revisions;
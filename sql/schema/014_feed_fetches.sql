@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE feed_fetches (
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    http_status INTEGER,
+    bytes BIGINT NOT NULL,
+    item_count INTEGER NOT NULL,
+    new_post_count INTEGER NOT NULL,
+    updated_post_count INTEGER NOT NULL,
+    error TEXT
+);
+
+CREATE INDEX feed_fetches_feed_id_idx ON feed_fetches (feed_id, started_at DESC);
+
+-- +goose Down
+DROP TABLE feed_fetches;
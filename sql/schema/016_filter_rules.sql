@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    hide BOOLEAN NOT NULL DEFAULT FALSE,
+    bookmark BOOLEAN NOT NULL DEFAULT FALSE,
+    tag TEXT
+);
+
+CREATE INDEX filter_rules_user_id_idx ON filter_rules (user_id);
+
+-- Posts hidden by a rule; deleting the rule unhides them
+CREATE TABLE hidden_posts (
+    rule_id UUID NOT NULL REFERENCES filter_rules(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (rule_id, post_id)
+);
+
+CREATE INDEX hidden_posts_user_id_post_id_idx ON hidden_posts (user_id, post_id);
+
+CREATE TABLE post_tags (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id, tag)
+);
+
+-- +goose Down
+DROP TABLE post_tags;
+DROP TABLE hidden_posts;
+DROP TABLE filter_rules;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- When each user's scheduled digest was last sent, so that aggregators
+-- sharing a database send it only once a day
+CREATE TABLE digest_sends (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    sent_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE digest_sends;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Per-follower settings: a display name overriding the feed's, a fetch
+-- priority, and muting, which keeps the feed out of the follower's stream
+ALTER TABLE feed_follows ADD COLUMN title TEXT;
+ALTER TABLE feed_follows ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feed_follows ADD COLUMN muted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN muted;
+ALTER TABLE feed_follows DROP COLUMN priority;
+ALTER TABLE feed_follows DROP COLUMN title;